@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"mcp-obsidian/obsidian/client"
+	obsidianHandlers "mcp-obsidian/obsidian/handlers"
+	"mcp-obsidian/obsidian/links"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintLinksChecks string
+	lintLinksFolder string
+	lintLinksJSON   bool
+)
+
+// lintLinksCmd represents the lint-links command
+var lintLinksCmd = &cobra.Command{
+	Use:   "lint-links",
+	Short: "Report broken links, orphan notes and unused attachments",
+	Long: `Scan the vault configured through the environment and report unresolved
+wikilinks, links to missing headings or block IDs, notes no other note links
+to and attachments nothing references. Each finding is printed as
+path:line: check: message. Exits with status 1 when anything is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		backend, err := client.NewVaultBackend(client.LoadConfigFromEnv())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to initialize vault backend: %v\n", err)
+			os.Exit(2)
+		}
+
+		findings, notesChecked, err := obsidianHandlers.LintVault(backend, lintLinksChecks, lintLinksFolder)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lint failed: %v\n", err)
+			os.Exit(2)
+		}
+
+		if lintLinksJSON {
+			data, err := json.MarshalIndent(map[string]interface{}{
+				"total_findings": len(findings),
+				"notes_checked":  notesChecked,
+				"counts":         links.CountFindings(findings),
+				"findings":       findings,
+			}, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to marshal JSON: %v\n", err)
+				os.Exit(2)
+			}
+			fmt.Println(string(data))
+		} else {
+			for _, finding := range findings {
+				fmt.Printf("%s:%d: %s: %s\n", finding.Path, finding.Line, finding.Kind, finding.Message)
+			}
+			counts := links.CountFindings(findings)
+			fmt.Fprintf(os.Stderr, "\n%d notes checked, %d findings\n", notesChecked, len(findings))
+			for _, check := range links.LintChecks {
+				if counts[check] > 0 {
+					fmt.Fprintf(os.Stderr, "  %s: %d\n", check, counts[check])
+				}
+			}
+		}
+
+		if len(findings) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintLinksCmd)
+
+	lintLinksCmd.Flags().StringVar(&lintLinksChecks, "checks", "", "Comma-separated checks to run: unresolved_link, missing_heading, missing_block, orphan_note, unused_attachment (default: all)")
+	lintLinksCmd.Flags().StringVar(&lintLinksFolder, "folder", "", "Only report files below this folder")
+	lintLinksCmd.Flags().BoolVar(&lintLinksJSON, "json", false, "Print findings as JSON")
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 
+	"mcp-obsidian/obsidian/client"
 	obsidianHandlers "mcp-obsidian/obsidian/handlers"
 	"mcp-obsidian/obsidian/logger"
 	"mcp-obsidian/obsidian/middleware"
@@ -48,6 +49,9 @@ var obsidianMcpCmd = &cobra.Command{
 		// Check environment
 		checkObsidianMCPEnvironment()
 
+		// Build the vault backend once and inject it into the handlers
+		initVaultBackend()
+
 		// Log server startup completion
 		totalStartupTime := time.Since(startTime)
 		logger.LogServerEvent("server_startup_complete", "Obsidian MCP Server startup completed", map[string]interface{}{
@@ -238,16 +242,21 @@ func registerObsidianTools(s *server.MCPServer) {
 
 	// List files in directory tool
 	listFilesInDirTool := mcp.NewTool("obsidian_list_files_in_dir",
-		mcp.WithDescription("List files in a specific directory"),
-		mcp.WithString("dirpath", mcp.Required(), mcp.Description("Directory path to list files from")),
-		mcp.WithString("max_depth", mcp.Description("Maximum depth to explore (default: 3, use 0 for unlimited)")),
+		mcp.WithDescription("List files in a directory recursively as a nested tree with size and modification time. Hidden folders such as .obsidian and .trash are skipped"),
+		mcp.WithString("dirpath", mcp.Required(), mcp.Description("Directory path to list files from ('' or '/' for the vault root)")),
+		mcp.WithString("max_depth", mcp.Description("Maximum depth to explore (default: 3, use 0 for unlimited). Folders at the limit are marked truncated")),
+		mcp.WithString("include", mcp.Description("Comma-separated globs files must match, e.g. '*.md' or 'Projects/**/*.canvas'. Patterns without '/' match the file name")),
+		mcp.WithString("exclude", mcp.Description("Comma-separated globs of files and folders to leave out, e.g. 'Archive,*.excalidraw.md'")),
+		mcp.WithString("extensions", mcp.Description("Comma-separated file extensions to list, e.g. 'md,canvas' (default: all)")),
+		mcp.WithString("include_hidden", mcp.Description("List hidden files and folders such as .obsidian and .trash when the backend returns them (true/false, default: false)")),
 	)
 	s.AddTool(listFilesInDirTool, middleware.LoggingMiddleware(obsidianHandlers.ListFilesInDir))
 
 	// Get file contents tool
 	getFileContentsTool := mcp.NewTool("obsidian_get_file_contents",
-		mcp.WithDescription("Get the contents of a file"),
+		mcp.WithDescription("Get the contents of a file. With lines, return only that range with numbered lines and the hash a lines patch takes as expected_hash"),
 		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the file")),
+		mcp.WithString("lines", mcp.Description("1-based line or range like '12-15' to return numbered, with its hash for obsidian_patch_content")),
 	)
 	s.AddTool(getFileContentsTool, middleware.LoggingMiddleware(obsidianHandlers.GetFileContents))
 
@@ -264,6 +273,8 @@ func registerObsidianTools(s *server.MCPServer) {
 		mcp.WithDescription("Append content to a file"),
 		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the file")),
 		mcp.WithString("content", mcp.Required(), mcp.Description("Content to append")),
+		mcp.WithString("open_after", mcp.Description("Open the file in the Obsidian UI after writing: 'true' or 'false' (default: false)")),
+		mcp.WithString("new_leaf", mcp.Description("With open_after, open the file in a new leaf: 'true' or 'false' (default: false)")),
 	)
 	s.AddTool(appendContentTool, obsidianHandlers.AppendContent)
 
@@ -272,6 +283,8 @@ func registerObsidianTools(s *server.MCPServer) {
 		mcp.WithDescription("Create or update a file"),
 		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the file")),
 		mcp.WithString("content", mcp.Required(), mcp.Description("Content to write")),
+		mcp.WithString("open_after", mcp.Description("Open the file in the Obsidian UI after writing: 'true' or 'false' (default: false)")),
+		mcp.WithString("new_leaf", mcp.Description("With open_after, open the file in a new leaf: 'true' or 'false' (default: false)")),
 	)
 	s.AddTool(putContentTool, obsidianHandlers.PutContent)
 
@@ -309,25 +322,44 @@ TARGET TYPES:
    - Content should be the new field value (e.g., "in_progress" for status field)
    - For array fields like tags, use proper YAML format: "['tag1', 'tag2']"
 
+4. LINES TARGETS:
+   - Use a 1-based line or inclusive range (e.g., "12", "12-15")
+   - Works in notes without headings or block IDs
+   - Pass expected_hash to refuse the patch if the lines changed since you read them; every lines patch reports the hash of the lines it wrote, and a mismatch reports the current hash and lines
+   - The hash is the first 16 hex characters of the SHA-256 of the lines joined with "\n", without "\r", so you can compute it from lines you read yourself
+
+5. REGEX TARGETS:
+   - Use a regular expression (Go RE2 syntax, ^ and $ match at line boundaries)
+   - occurrence picks the Nth match (default 1, -1 for the last)
+   - replace and delete act on the matched text; prepend and append insert lines before or after the line(s) of the match
+
 OPERATIONS:
 - append: Add content after the target element
 - prepend: Add content before the target element  
 - replace: Replace the target element's content entirely
+- delete: Remove the target (lines and regex targets only, content not needed)
 
 EXAMPLES:
 - Update status: target_type="frontmatter", target="status", content="completed"
 - Add to heading: target_type="heading", target="Notes", operation="append", content="\n\nAdditional notes here"
 - Replace block: target_type="block", target="abc123", operation="replace", content="New content"
+- Replace lines: target_type="lines", target="3-4", operation="replace", content="New line 3\nNew line 4"
+- Tick a checkbox: target_type="regex", target="- \[ \] Buy milk", operation="replace", content="- [x] Buy milk"
 
 TIPS:
 - Always use discover_structure first to see available targets
+- For lines targets, read the range with obsidian_get_file_contents and lines="3-4" first and pass the hash it reports as expected_hash
 - For frontmatter, use simple string values unless you know the field expects arrays/objects
 - Test with small changes first to verify the target works correctly`),
 		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the file relative to vault root. Examples: 'Projects/task.md', 'Notes/meeting-notes.md', 'Tasks/AWS_Security_Audit/progress/completed_steps.md'")),
-		mcp.WithString("operation", mcp.Required(), mcp.Description("Operation to perform: 'append' (add content after the target element), 'prepend' (add content before the target element), 'replace' (completely replace the target element's content)")),
-		mcp.WithString("target_type", mcp.Required(), mcp.Description("Type of target element: 'heading' (target should be exact heading text), 'block' (target should be block ID like 'abc123'), 'frontmatter' (target should be field name like 'status' or 'tags')")),
+		mcp.WithString("operation", mcp.Required(), mcp.Description("Operation to perform: 'append' (add content after the target element), 'prepend' (add content before the target element), 'replace' (completely replace the target element's content), 'delete' (remove the target, lines and regex targets only)")),
+		mcp.WithString("target_type", mcp.Required(), mcp.Description("Type of target element: 'heading' (target should be exact heading text), 'block' (target should be block ID like 'abc123'), 'frontmatter' (target should be field name like 'status' or 'tags'), 'lines' (target should be a 1-based line or range like '12-15'), 'regex' (target should be a regular expression)")),
 		mcp.WithString("target", mcp.Required(), mcp.Description("Target identifier: For headings use exact heading text (case-sensitive), for blocks use block ID, for frontmatter use field name. Use discover_structure to find exact target names.")),
-		mcp.WithString("content", mcp.Required(), mcp.Description("Content to patch: For frontmatter use the new field value (e.g., 'completed' for status field), for headings/blocks use markdown content. Include newlines with \\n for proper formatting.")),
+		mcp.WithString("content", mcp.Description("Content to patch: For frontmatter use the new field value (e.g., 'completed' for status field), for headings/blocks/lines/regex use markdown content. Include newlines with \\n for proper formatting. Required except for delete.")),
+		mcp.WithString("expected_hash", mcp.Description("For lines targets: hash the lines must still have: the first 16 hex characters of the SHA-256 of the lines joined with \\n and without \\r, as reported by obsidian_get_file_contents with lines, a previous lines patch or a hash mismatch error")),
+		mcp.WithString("occurrence", mcp.Description("For regex targets: which match to patch, 1-based, -1 for the last (default: 1)")),
+		mcp.WithString("open_after", mcp.Description("Open the file in the Obsidian UI after writing: 'true' or 'false' (default: false)")),
+		mcp.WithString("new_leaf", mcp.Description("With open_after, open the file in a new leaf: 'true' or 'false' (default: false)")),
 	)
 	s.AddTool(patchContentTool, obsidianHandlers.PatchContent)
 
@@ -361,6 +393,11 @@ WHAT IT SHOWS:
    - Properly formatted for copy-paste usage
    - Case-sensitive and whitespace-preserved
 
+5. OBSIDIAN ELEMENTS:
+   - Callouts with type, title and fold state
+   - Embeds and wikilinks with target, heading, block and alias
+   - %%comments%%, ==highlights==, $$math$$ blocks, footnotes and Mermaid diagrams
+
 OUTPUT FORMATS:
 - Structured JSON with hierarchical data
 - Human-readable format with patch targets
@@ -469,35 +506,197 @@ TIPS:
 	s.AddTool(searchJSONTool, obsidianHandlers.SearchJSON)
 
 	// Periodic notes tools
+	periodParam := mcp.WithString("period", mcp.Required(), mcp.Description("Period type: daily, weekly, monthly, quarterly, yearly"))
+	periodicDateParam := mcp.WithString("date", mcp.Description("Date of any day inside the period: YYYY-MM-DD, 'today', 'yesterday', 'last monday', '3 days ago', 'last week', '2026-W14' (ISO week), '2026-Q3', '2026-10' or '2026'. Resolved in OBSIDIAN_TIMEZONE. Omit for the current period"))
+
 	getPeriodicNoteTool := mcp.NewTool("obsidian_get_periodic_note",
-		mcp.WithDescription("Get or create a periodic note (daily, weekly, monthly, quarterly, yearly)"),
-		mcp.WithString("period", mcp.Required(), mcp.Description("Period type: daily, weekly, monthly, quarterly, yearly")),
-		mcp.WithString("date", mcp.Required(), mcp.Description("Date in YYYY-MM-DD format")),
+		mcp.WithDescription("Get a periodic note (daily, weekly, monthly, quarterly, yearly) for a date or the current period"),
+		periodParam,
+		periodicDateParam,
 	)
 	s.AddTool(getPeriodicNoteTool, obsidianHandlers.GetPeriodicNote)
 
 	createPeriodicNoteTool := mcp.NewTool("obsidian_create_periodic_note",
-		mcp.WithDescription("Create a new periodic note"),
-		mcp.WithString("period", mcp.Required(), mcp.Description("Period type: daily, weekly, monthly, quarterly, yearly")),
-		mcp.WithString("date", mcp.Required(), mcp.Description("Date in YYYY-MM-DD format")),
+		mcp.WithDescription("Append content to a periodic note, creating it from the configured template if it does not exist"),
+		periodParam,
+		periodicDateParam,
 		mcp.WithString("content", mcp.Required(), mcp.Description("Content for the periodic note")),
 	)
 	s.AddTool(createPeriodicNoteTool, obsidianHandlers.CreatePeriodicNote)
 
+	putPeriodicNoteTool := mcp.NewTool("obsidian_put_periodic_note",
+		mcp.WithDescription("Replace the entire content of a periodic note"),
+		periodParam,
+		periodicDateParam,
+		mcp.WithString("content", mcp.Required(), mcp.Description("New content for the periodic note")),
+	)
+	s.AddTool(putPeriodicNoteTool, obsidianHandlers.PutPeriodicNote)
+
+	patchPeriodicNoteTool := mcp.NewTool("obsidian_patch_periodic_note",
+		mcp.WithDescription("Patch a periodic note relative to a heading, block or frontmatter field. Works like obsidian_patch_content with a period and date instead of a filepath."),
+		periodParam,
+		periodicDateParam,
+		mcp.WithString("operation", mcp.Required(), mcp.Description("Operation to perform: 'append', 'prepend' or 'replace'")),
+		mcp.WithString("target_type", mcp.Required(), mcp.Description("Type of target element: 'heading', 'block' or 'frontmatter'")),
+		mcp.WithString("target", mcp.Required(), mcp.Description("Heading text (nested with ' -> ' or '::'), block ID or frontmatter field name")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Content to patch")),
+	)
+	s.AddTool(patchPeriodicNoteTool, obsidianHandlers.PatchPeriodicNote)
+
+	deletePeriodicNoteTool := mcp.NewTool("obsidian_delete_periodic_note",
+		mcp.WithDescription("Delete a periodic note"),
+		periodParam,
+		periodicDateParam,
+		mcp.WithString("confirm", mcp.Description("Must be 'true' to confirm deletion")),
+	)
+	s.AddTool(deletePeriodicNoteTool, obsidianHandlers.DeletePeriodicNote)
+
+	periodicRollupTool := mcp.NewTool("obsidian_periodic_rollup",
+		mcp.WithDescription("Merge every periodic note in a date range into one digest: a section per note, optionally limited to selected headings, plus open tasks carried forward (unchecked or in progress, deduplicated, with the note and line where each was last seen; a task completed or cancelled in a later note is dropped)"),
+		mcp.WithString("period", mcp.Description("Period type: daily (default), weekly, monthly, quarterly, yearly")),
+		mcp.WithString("from", mcp.Required(), mcp.Description("Start of the range: YYYY-MM-DD or an expression such as 'last week', '7 days ago', '2026-W14', '2026-Q3'")),
+		mcp.WithString("to", mcp.Description("End of the range (inclusive), same formats as from. Defaults to the end of the from period, e.g. from='last week' covers that whole week")),
+		mcp.WithString("headings", mcp.Description("Comma-separated headings to include from each note, e.g. 'Log' or '## Log, Decisions'. Default: whole note")),
+		mcp.WithString("include_tasks", mcp.Description("Collect open tasks across the notes: 'true' (default) or 'false'")),
+		mcp.WithString("format", mcp.Description("Output format: 'json' (default) or 'markdown' (merged digest document)")),
+	)
+	s.AddTool(periodicRollupTool, obsidianHandlers.PeriodicRollup)
+
+	// Task tools
+	listTasksTool := mcp.NewTool("obsidian_list_tasks",
+		mcp.WithDescription("List checkbox tasks across the vault with their status, Tasks plugin metadata (📅 due, ⏳ scheduled, 🛫 start, ✅ done, 🔁 recurrence, ⏫ priority), tags, nesting and line number"),
+		mcp.WithString("status", mcp.Description("Comma-separated statuses: todo, done, in_progress, cancelled, other, open (todo and in_progress) or status characters such as '/' (default: all)")),
+		mcp.WithString("due_from", mcp.Description("Only tasks due on or after this date: YYYY-MM-DD or an expression such as 'today' or 'this week'")),
+		mcp.WithString("due_to", mcp.Description("Only tasks due on or before this date (inclusive), same formats as due_from, e.g. 'today' for overdue and due tasks")),
+		mcp.WithString("tag", mcp.Description("Only tasks carrying this tag or one of its nested tags, e.g. 'project'")),
+		mcp.WithString("folder", mcp.Description("Only tasks in notes below this folder")),
+		mcp.WithString("limit", mcp.Description("Maximum number of tasks to return (default: 0, unlimited)")),
+	)
+	s.AddTool(listTasksTool, obsidianHandlers.ListTasks)
+
+	updateTaskTool := mcp.NewTool("obsidian_update_task",
+		mcp.WithDescription("Update a checkbox task addressed by file plus line number or block ID: toggle, complete with a ✅ done date, cancel, reopen, start, set a status character or reschedule its dates. Completing a task with a 🔁 recurrence rule inserts its next occurrence above it with the dates moved forward, as the Tasks plugin does"),
+		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the note containing the task")),
+		mcp.WithString("line", mcp.Description("1-based line of the task, as returned by obsidian_list_tasks")),
+		mcp.WithString("block_id", mcp.Description("Block ID of the task, with or without ^; used instead of line")),
+		mcp.WithString("operation", mcp.Description("Operation: toggle (default), complete, cancel, reopen, start (in progress), set_status, reschedule")),
+		mcp.WithString("status", mcp.Description("For set_status: a status name (todo, done, in_progress, cancelled) or a single status character")),
+		mcp.WithString("due", mcp.Description("For reschedule: new due date as YYYY-MM-DD or an expression such as 'next friday'; 'none' removes it")),
+		mcp.WithString("scheduled", mcp.Description("For reschedule: new scheduled date; 'none' removes it")),
+		mcp.WithString("start", mcp.Description("For reschedule: new start date; 'none' removes it")),
+		mcp.WithString("done_date", mcp.Description("For complete and cancel: date to record (default: today)")),
+	)
+	s.AddTool(updateTaskTool, obsidianHandlers.UpdateTask)
+
+	// Table tools
+	readTableTool := mcp.NewTool("obsidian_read_table",
+		mcp.WithDescription("Read a Markdown table as JSON rows keyed by column header, with its columns, alignments and line range. Address the table by heading and/or its 1-based index"),
+		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the note containing the table")),
+		mcp.WithString("heading", mcp.Description("Heading whose section contains the table; use Parent::Child for nested headings (default: whole note)")),
+		mcp.WithString("index", mcp.Description("1-based index of the table within the heading's section or the note (default: 1)")),
+	)
+	s.AddTool(readTableTool, obsidianHandlers.ReadTable)
+
+	updateTableTool := mcp.NewTool("obsidian_update_table",
+		mcp.WithDescription("Edit a Markdown table structurally instead of as text: add, update or delete rows, add, delete or rename columns, or sort. The table is re-rendered with aligned pipes and written back by patching the section of its heading"),
+		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the note containing the table")),
+		mcp.WithString("operation", mcp.Required(), mcp.Description("Operation: add_row, update_rows, delete_rows, add_column, delete_column, rename_column, sort")),
+		mcp.WithString("heading", mcp.Description("Heading whose section contains the table; use Parent::Child for nested headings (default: whole note)")),
+		mcp.WithString("index", mcp.Description("1-based index of the table within the heading's section or the note (default: 1)")),
+		mcp.WithString("row", mcp.Description("For add_row and update_rows: JSON object of column values, e.g. {\"Status\": \"Done\"}")),
+		mcp.WithString("where", mcp.Description("For update_rows and delete_rows: JSON object of column values a row must match (case-insensitive), e.g. {\"Task\": \"Write docs\"}")),
+		mcp.WithString("row_index", mcp.Description("For update_rows and delete_rows: 1-based row number, used instead of where")),
+		mcp.WithString("column", mcp.Description("For add_column, delete_column, rename_column and sort: column header")),
+		mcp.WithString("new_name", mcp.Description("For rename_column: new column header")),
+		mcp.WithString("value", mcp.Description("For add_column: value for existing rows (default: empty)")),
+		mcp.WithString("descending", mcp.Description("For sort: 'true' to sort in descending order (default: false). Numbers sort numerically, empty cells last")),
+	)
+	s.AddTool(updateTableTool, obsidianHandlers.UpdateTable)
+
 	// Recent changes tool
 	getRecentChangesTool := mcp.NewTool("obsidian_get_recent_changes",
-		mcp.WithDescription("Get recent changes in the vault"),
-		mcp.WithString("limit", mcp.Description("Number of changes to return (default: 10)")),
-		mcp.WithString("days", mcp.Description("Number of days to look back (default: 7)")),
+		mcp.WithDescription("Get the most recently created or modified notes in the vault, newest first. Without the Local REST API, notes are only reported as created where the filesystem records file birth times"),
+		mcp.WithString("limit", mcp.Description("Number of changes to return (default: 10, 0 for unlimited)")),
+		mcp.WithString("days", mcp.Description("Number of days to look back (default: 7, 0 for no time window)")),
+		mcp.WithString("folder", mcp.Description("Only include files inside this folder, e.g. 'Projects/Alpha'")),
+		mcp.WithString("extensions", mcp.Description("Comma-separated file extensions to include (default: 'md', use 'all' for every file)")),
 	)
 	s.AddTool(getRecentChangesTool, obsidianHandlers.GetRecentChanges)
 
 	// Tags tool
 	getTagsTool := mcp.NewTool("obsidian_get_tags",
-		mcp.WithDescription("Get all tags in the vault"),
+		mcp.WithDescription("Get all tags in the vault with the number of notes and the files using each tag. Nested tags like #project/alpha also count towards #project unless nested is 'false'."),
+		mcp.WithString("prefix", mcp.Description("Only return tags starting with this prefix, e.g. 'project' or '#project/'")),
+		mcp.WithString("sort", mcp.Description("Sort order: 'count' (most used first, default) or 'name'")),
+		mcp.WithString("nested", mcp.Description("Roll nested tags up into their parent tags (true/false, default: true)")),
+		mcp.WithString("limit", mcp.Description("Maximum number of tags to return (default: 0, unlimited)")),
 	)
 	s.AddTool(getTagsTool, obsidianHandlers.GetTags)
 
+	// Link graph tools
+	getBacklinksTool := mcp.NewTool("obsidian_get_backlinks",
+		mcp.WithDescription("List the links from other notes to a note or attachment: wikilinks, embeds, heading/block links and relative markdown links, with the linking note and line of each"),
+		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the file relative to vault root, or a note name as used in wikilinks")),
+		mcp.WithString("include_embeds", mcp.Description("Include ![[embeds]] and images (true/false, default: true)")),
+	)
+	s.AddTool(getBacklinksTool, obsidianHandlers.GetBacklinks)
+
+	getOutgoingLinksTool := mcp.NewTool("obsidian_get_outgoing_links",
+		mcp.WithDescription("List the links in a note with their line, heading/block anchor, alias and the vault path each one resolves to (empty when unresolved)"),
+		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the note relative to vault root, or a note name as used in wikilinks")),
+	)
+	s.AddTool(getOutgoingLinksTool, obsidianHandlers.GetOutgoingLinks)
+
+	getNeighborsTool := mcp.NewTool("obsidian_get_neighbors",
+		mcp.WithDescription("Walk the link graph around a note and list the notes and attachments within a number of links, with their distance and the note they were reached from"),
+		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the note relative to vault root, or a note name as used in wikilinks")),
+		mcp.WithString("depth", mcp.Description("Number of links to follow, 1-5 (default: 1)")),
+		mcp.WithString("direction", mcp.Description("Links to follow: 'both' (default), 'outgoing' or 'incoming'")),
+	)
+	s.AddTool(getNeighborsTool, obsidianHandlers.GetNeighbors)
+
+	lintLinksTool := mcp.NewTool("obsidian_lint_links",
+		mcp.WithDescription("Check vault link hygiene: unresolved wikilinks, links to missing headings or block IDs, orphan notes with no inbound links and attachments nothing references. Each finding has a file path and line number. Also available as the lint-links CLI command."),
+		mcp.WithString("checks", mcp.Description("Comma-separated checks: unresolved_link, missing_heading, missing_block, orphan_note, unused_attachment (default: all)")),
+		mcp.WithString("folder", mcp.Description("Only report files below this folder, e.g. 'Projects'")),
+		mcp.WithString("limit", mcp.Description("Maximum number of findings to return (default: 0, unlimited)")),
+	)
+	s.AddTool(lintLinksTool, obsidianHandlers.LintLinks)
+
+	moveFileTool := mcp.NewTool("obsidian_move_file",
+		mcp.WithDescription("Move or rename a note, attachment or folder and rewrite every wikilink, embed and markdown link that points to it. Links keep their style: shortest-name links stay as short as possible, vault paths stay vault paths and relative links are recomputed. Returns every file touched and each link rewritten."),
+		mcp.WithString("filepath", mcp.Required(), mcp.Description("File or folder to move, relative to vault root")),
+		mcp.WithString("destination", mcp.Required(), mcp.Description("New path relative to vault root. End with '/' to move into a folder and keep the name; a note renamed without an extension keeps its extension")),
+		mcp.WithString("dry_run", mcp.Description("Only report what would be moved and rewritten, without changing the vault (true/false, default: false)")),
+	)
+	s.AddTool(moveFileTool, obsidianHandlers.MoveFile)
+
+	copyFileTool := mcp.NewTool("obsidian_copy_file",
+		mcp.WithDescription("Duplicate a note, attachment or whole folder tree, attachments included, to a new path. Path and relative links in the copy are updated so links within the copied tree point at the copies and links outside it still resolve; bare note names are left as written. Attachments can only be copied with the filesystem backend; a failed copy removes the files it already wrote."),
+		mcp.WithString("filepath", mcp.Required(), mcp.Description("File or folder to copy, relative to vault root")),
+		mcp.WithString("destination", mcp.Required(), mcp.Description("Path of the copy relative to vault root. End with '/' to copy into a folder and keep the name")),
+		mcp.WithString("rewrite_links", mcp.Description("Rewrite path and relative links in the copy (true/false, default: true)")),
+		mcp.WithString("regenerate_block_ids", mcp.Description("Give every ^block-id in the copy a new ID and update references to it within the copy (true/false, default: false)")),
+	)
+	s.AddTool(copyFileTool, obsidianHandlers.CopyFile)
+
+	// Find and replace tool
+	findReplaceTool := mcp.NewTool("obsidian_find_replace",
+		mcp.WithDescription("Find and replace literal text or regex matches across the notes of a folder or glob, e.g. to rename a project or person. By default this is a dry run that previews a unified diff per file; pass dry_run 'false' to write the changes. Notes that cannot be read are listed under notes_unreadable; the result lists the replacements per file"),
+		mcp.WithString("find", mcp.Required(), mcp.Description("Text to find, or a regular expression (Go RE2 syntax) when regex is 'true'; ^ and $ match at line boundaries")),
+		mcp.WithString("replace", mcp.Description("Replacement text (default: empty, deleting the matches). With regex, $1 or ${name} insert capture groups")),
+		mcp.WithString("regex", mcp.Description("Treat find as a regular expression: 'true' or 'false' (default: false)")),
+		mcp.WithString("case_sensitive", mcp.Description("Match case: 'true' or 'false' (default: true)")),
+		mcp.WithString("whole_word", mcp.Description("Only match whole words: 'true' or 'false' (default: false)")),
+		mcp.WithString("folder", mcp.Description("Only notes below this folder (default: whole vault)")),
+		mcp.WithString("include", mcp.Description("Comma-separated globs the note paths must match, e.g. 'Projects/**/*.md' or 'meeting-*.md'")),
+		mcp.WithString("scope", mcp.Description("Part of each note to change: 'all' (default), 'body' (skip frontmatter) or 'frontmatter'")),
+		mcp.WithString("skip_code", mcp.Description("Leave code blocks and inline code untouched: 'true' or 'false' (default: false)")),
+		mcp.WithString("dry_run", mcp.Description("Preview the changes as unified diffs without writing: 'true' (default) or 'false' to write the changes")),
+		mcp.WithString("context_lines", mcp.Description("Unchanged lines shown around each change in dry run diffs (default: 3)")),
+	)
+	s.AddTool(findReplaceTool, obsidianHandlers.FindReplace)
+
 	// Frontmatter tools
 	getFrontmatterTool := mcp.NewTool("obsidian_get_frontmatter",
 		mcp.WithDescription("Get frontmatter from a file"),
@@ -506,26 +705,96 @@ TIPS:
 	s.AddTool(getFrontmatterTool, obsidianHandlers.GetFrontmatter)
 
 	setFrontmatterTool := mcp.NewTool("obsidian_set_frontmatter",
-		mcp.WithDescription("Set frontmatter for a file"),
+		mcp.WithDescription("Edit frontmatter for a file. Only the edited fields are rewritten; other fields, comments, blank lines and indentation are kept as written. Values are parsed as JSON, so arrays, numbers, booleans and objects keep their type; anything that is not valid JSON is stored as a string."),
 		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the file")),
-		mcp.WithString("data", mcp.Required(), mcp.Description("JSON string of frontmatter data")),
+		mcp.WithString("operation", mcp.Description("Operation: 'set' (default) replaces the field, 'add' adds values to a list field, 'remove' removes values from a list field, 'delete' removes the field")),
+		mcp.WithString("field", mcp.Description("Frontmatter field to edit. Required unless data is given")),
+		mcp.WithString("value", mcp.Description("Value as JSON or plain text, e.g. '[\"project\", \"active\"]', '3', 'true' or 'In progress'. Numbers that would change when reformatted, such as '1.10' or '007', stay text; dates such as 2026-01-01 are written unquoted. For add and remove, a JSON array adds or removes several items. Not used by delete")),
+		mcp.WithString("data", mcp.Description("JSON object of fields to set in one call, e.g. '{\"status\": \"done\", \"priority\": 2}'. New fields are added in the order given and the note is written once. Only for operation set")),
 	)
 	s.AddTool(setFrontmatterTool, obsidianHandlers.SetFrontmatter)
 
 	// Block reference tool
 	getBlockReferenceTool := mcp.NewTool("obsidian_get_block_reference",
-		mcp.WithDescription("Get a block reference from a file"),
-		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the file")),
-		mcp.WithString("block_id", mcp.Required(), mcp.Description("Block ID to retrieve")),
+		mcp.WithDescription("Get the paragraph, list item or table carrying a block ID (^blockid), with its line range and enclosing heading path. Searches the whole vault when no filepath is given."),
+		mcp.WithString("filepath", mcp.Description("Path to the file. Omit to look the block ID up across the vault")),
+		mcp.WithString("block_id", mcp.Required(), mcp.Description("Block ID to retrieve, with or without the leading ^")),
 	)
 	s.AddTool(getBlockReferenceTool, obsidianHandlers.GetBlockReference)
 
+	// Dataview search tool
+	searchDataviewTool := mcp.NewTool("obsidian_search_dataview",
+		mcp.WithDescription("Run a Dataview DQL TABLE query (requires the Dataview plugin) and return one row per matching file with its column values as JSON"),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Dataview TABLE query, e.g. 'TABLE status, due AS \"Due\" FROM #project WHERE status != \"done\" SORT due ASC'")),
+	)
+	s.AddTool(searchDataviewTool, obsidianHandlers.SearchDataview)
+
+	// Open file tool
+	openFileTool := mcp.NewTool("obsidian_open_file",
+		mcp.WithDescription("Open a note in the Obsidian user interface so the user can see it. Obsidian creates the note if it does not exist."),
+		mcp.WithString("filepath", mcp.Required(), mcp.Description("Path to the file relative to vault root")),
+		mcp.WithString("new_leaf", mcp.Description("Open the note in a new leaf (tab): 'true' or 'false' (default: false)")),
+	)
+	s.AddTool(openFileTool, obsidianHandlers.OpenFile)
+
+	// Active file tools
+	getActiveFileTool := mcp.NewTool("obsidian_get_active_file",
+		mcp.WithDescription("Get the note currently open in Obsidian"),
+		mcp.WithString("format", mcp.Description("Output format: 'markdown' (default) or 'json' (content plus path, frontmatter, tags and file stats)")),
+	)
+	s.AddTool(getActiveFileTool, obsidianHandlers.GetActiveFile)
+
+	putActiveFileTool := mcp.NewTool("obsidian_put_active_file",
+		mcp.WithDescription("Replace the content of the note currently open in Obsidian"),
+		mcp.WithString("content", mcp.Required(), mcp.Description("New content for the active note")),
+	)
+	s.AddTool(putActiveFileTool, obsidianHandlers.PutActiveFile)
+
+	appendActiveFileTool := mcp.NewTool("obsidian_append_active_file",
+		mcp.WithDescription("Append content to the end of the note currently open in Obsidian"),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Content to append")),
+	)
+	s.AddTool(appendActiveFileTool, obsidianHandlers.AppendActiveFile)
+
+	patchActiveFileTool := mcp.NewTool("obsidian_patch_active_file",
+		mcp.WithDescription("Patch the note currently open in Obsidian relative to a heading, block or frontmatter field. Works like obsidian_patch_content without a filepath."),
+		mcp.WithString("operation", mcp.Required(), mcp.Description("Operation to perform: 'append', 'prepend' or 'replace'")),
+		mcp.WithString("target_type", mcp.Required(), mcp.Description("Type of target element: 'heading', 'block' or 'frontmatter'")),
+		mcp.WithString("target", mcp.Required(), mcp.Description("Heading text (nested with ' -> ' or '::'), block ID or frontmatter field name")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Content to patch")),
+	)
+	s.AddTool(patchActiveFileTool, obsidianHandlers.PatchActiveFile)
+
+	deleteActiveFileTool := mcp.NewTool("obsidian_delete_active_file",
+		mcp.WithDescription("Delete the note currently open in Obsidian"),
+		mcp.WithString("confirm", mcp.Description("Must be 'true' to confirm deletion")),
+	)
+	s.AddTool(deleteActiveFileTool, obsidianHandlers.DeleteActiveFile)
+
+	// Command palette tools
+	listCommandsTool := mcp.NewTool("obsidian_list_commands",
+		mcp.WithDescription("List Obsidian command palette commands with their IDs, fuzzy-filtered by name or ID. Each entry reports whether the server's command policy allows executing it."),
+		mcp.WithString("query", mcp.Description("Fuzzy filter matched against command names and IDs (e.g. 'graph', 'toggle fold')")),
+		mcp.WithString("limit", mcp.Description("Maximum number of commands to return (default: all)")),
+		mcp.WithString("allowed_only", mcp.Description("Only return commands the policy allows executing: 'true' or 'false' (default: false)")),
+	)
+	s.AddTool(listCommandsTool, obsidianHandlers.ListCommands)
+
+	executeCommandTool := mcp.NewTool("obsidian_execute_command",
+		mcp.WithDescription("Execute an Obsidian command by ID. Use obsidian_list_commands to find IDs. Commands blocked by OBSIDIAN_COMMAND_ALLOWLIST/OBSIDIAN_COMMAND_DENYLIST are rejected."),
+		mcp.WithString("command_id", mcp.Required(), mcp.Description("Command ID (e.g. 'graph:open')")),
+	)
+	s.AddTool(executeCommandTool, obsidianHandlers.ExecuteCommand)
+
 	fmt.Fprintf(os.Stderr, "✅ Obsidian tools registered successfully!\n")
 	logger.LogInfo("Obsidian tools registered successfully", nil)
 }
 
 func checkObsidianMCPEnvironment() {
 	requiredEnvVars := []string{"OBSIDIAN_API_KEY"}
+	if client.LoadConfigFromEnv().Backend == client.BackendFilesystem {
+		requiredEnvVars = []string{"OBSIDIAN_VAULT_PATH"}
+	}
 	missingVars := []string{}
 
 	for _, envVar := range requiredEnvVars {
@@ -547,6 +816,26 @@ func checkObsidianMCPEnvironment() {
 	logger.LogInfo("Checking Obsidian connection", nil)
 }
 
+// initVaultBackend builds the configured vault backend and injects it into the handlers.
+// A failure is only logged so the server still starts; tools report the error when called.
+func initVaultBackend() {
+	config := client.LoadConfigFromEnv()
+	backend, err := client.NewVaultBackend(config)
+	if err != nil {
+		logger.LogError(err, "Failed to initialize vault backend", map[string]interface{}{
+			"backend": config.Backend,
+		})
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to initialize vault backend: %v\n", err)
+		return
+	}
+
+	obsidianHandlers.SetBackend(backend)
+	logger.LogInfo("Vault backend initialized", map[string]interface{}{
+		"backend": config.Backend,
+	})
+	fmt.Fprintf(os.Stderr, "✅ Vault backend initialized (%s)\n", config.Backend)
+}
+
 // getTransportType returns a string describing the current transport configuration
 func getTransportType() string {
 	if obsidianUseStdio {
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"fmt"
+	"io"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// activeEndpoint is the Local REST API endpoint for the note currently open in Obsidian
+const activeEndpoint = "/active/"
+
+// GetActiveFile gets the markdown content of the note currently open in Obsidian
+func (c *ObsidianClient) GetActiveFile() (string, error) {
+	resp, err := c.makeRequest("GET", activeEndpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return string(bodyBytes), nil
+}
+
+// GetActiveNote gets the note currently open in Obsidian with its parsed metadata
+func (c *ObsidianClient) GetActiveNote() (*types.NoteJSON, error) {
+	return c.getNoteJSON(activeEndpoint)
+}
+
+// PutActiveFile replaces the content of the note currently open in Obsidian
+func (c *ObsidianClient) PutActiveFile(content string) error {
+	return c.sendMarkdown("PUT", activeEndpoint, content, "put active file")
+}
+
+// AppendActiveFile appends content to the note currently open in Obsidian
+func (c *ObsidianClient) AppendActiveFile(content string) error {
+	return c.sendMarkdown("POST", activeEndpoint, content, "append active file")
+}
+
+// PatchActiveFile patches the note currently open in Obsidian relative to a heading, block or frontmatter field
+func (c *ObsidianClient) PatchActiveFile(operation, targetType, target, content string) error {
+	return c.sendPatch(activeEndpoint, operation, targetType, target, content)
+}
+
+// DeleteActiveFile deletes the note currently open in Obsidian
+func (c *ObsidianClient) DeleteActiveFile() error {
+	resp, err := c.makeRequest("DELETE", activeEndpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
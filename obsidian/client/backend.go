@@ -0,0 +1,121 @@
+package client
+
+import (
+	"fmt"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// VaultBackend is the set of vault operations the MCP handlers depend on.
+// ObsidianClient and FilesystemClient both implement it, and decorators or
+// in-memory fakes can be swapped in without touching the handlers.
+//
+// The interfaces below are optional capabilities that handlers detect by
+// type assertion. A decorator hides them unless it implements each one the
+// wrapped backend has and forwards the calls.
+type VaultBackend interface {
+	TestConnection() error
+	ListFilesInVault() ([]types.FileInfo, error)
+	ListFilesInDir(dirPath string) ([]types.FileInfo, error)
+	GetFileContents(filePath string) (string, error)
+	GetNote(filePath string) (*types.NoteJSON, error)
+	Search(query string, contextLength int) ([]types.SearchResult, error)
+	AppendContent(filePath, content string) error
+	PutContent(filePath, content string) error
+	DeleteFile(filePath string) error
+	PatchContent(filePath, operation, targetType, target, content string) error
+	GetFrontmatter(filePath string) (*types.FrontmatterResponse, error)
+	SetFrontmatter(filePath, field string, value interface{}) error
+}
+
+// JSONSearchBackend is implemented by backends that support JsonLogic queries
+type JSONSearchBackend interface {
+	SearchJSON(query map[string]interface{}) ([]types.SearchResult, error)
+}
+
+// DataviewBackend is implemented by backends that can run Dataview DQL queries
+type DataviewBackend interface {
+	SearchDQL(query string) ([]types.DQLRow, error)
+}
+
+// PeriodicNoteBackend is implemented by backends that support the Periodic Notes plugin.
+// An empty date addresses the note for the current period.
+type PeriodicNoteBackend interface {
+	GetPeriodicNote(period, date string) (*types.PeriodicNoteResponse, error)
+	CreatePeriodicNote(period, date, content string) (*types.PeriodicNoteResponse, error)
+	PutPeriodicNote(period, date, content string) error
+	PatchPeriodicNote(period, date, operation, targetType, target, content string) error
+	DeletePeriodicNote(period, date string) error
+}
+
+// ActiveFileBackend is implemented by backends that can act on the note open in Obsidian
+type ActiveFileBackend interface {
+	GetActiveFile() (string, error)
+	GetActiveNote() (*types.NoteJSON, error)
+	PutActiveFile(content string) error
+	AppendActiveFile(content string) error
+	PatchActiveFile(operation, targetType, target, content string) error
+	DeleteActiveFile() error
+}
+
+// CommandBackend is implemented by backends that can run Obsidian command palette commands
+type CommandBackend interface {
+	ListCommands() ([]types.Command, error)
+	ExecuteCommand(commandID string) error
+	CommandAllowed(commandID string) bool
+}
+
+// OpenFileBackend is implemented by backends that can open notes in the Obsidian UI
+type OpenFileBackend interface {
+	OpenFile(filePath string, newLeaf bool) error
+}
+
+// MoveBackend is implemented by backends that can move or rename files and folders
+type MoveBackend interface {
+	MoveFile(src, dst string) error
+}
+
+// CopyBackend is implemented by backends that can copy files byte for byte,
+// which attachments need because PutContent may not preserve binary content
+type CopyBackend interface {
+	CopyFile(src, dst string) error
+}
+
+// Compile-time checks that the bundled clients satisfy the backend interfaces
+var (
+	_ VaultBackend        = (*ObsidianClient)(nil)
+	_ VaultBackend        = (*FilesystemClient)(nil)
+	_ JSONSearchBackend   = (*ObsidianClient)(nil)
+	_ DataviewBackend     = (*ObsidianClient)(nil)
+	_ PeriodicNoteBackend = (*ObsidianClient)(nil)
+	_ ActiveFileBackend   = (*ObsidianClient)(nil)
+	_ CommandBackend      = (*ObsidianClient)(nil)
+	_ OpenFileBackend     = (*ObsidianClient)(nil)
+	_ MoveBackend         = (*ObsidianClient)(nil)
+	_ MoveBackend         = (*FilesystemClient)(nil)
+	_ CopyBackend         = (*FilesystemClient)(nil)
+)
+
+// NewVaultBackend creates the backend selected by config.Backend
+func NewVaultBackend(config *types.ObsidianConfig) (VaultBackend, error) {
+	switch config.Backend {
+	case BackendFilesystem:
+		fsClient, err := NewFilesystemClient(config)
+		if err != nil {
+			return nil, err
+		}
+		return fsClient, nil
+	case BackendREST, "":
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("OBSIDIAN_API_KEY environment variable is required")
+		}
+		return NewObsidianClient(config), nil
+	default:
+		return nil, fmt.Errorf("unknown OBSIDIAN_BACKEND %q, expected %q or %q", config.Backend, BackendREST, BackendFilesystem)
+	}
+}
+
+// NewVaultBackendFromEnv creates the backend selected by OBSIDIAN_BACKEND
+func NewVaultBackendFromEnv() (VaultBackend, error) {
+	return NewVaultBackend(LoadConfigFromEnv())
+}
@@ -0,0 +1,18 @@
+//go:build darwin || freebsd || netbsd
+
+package client
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// birthTime returns when a file was created, from the birth time in its stat
+func birthTime(_ string, info fs.FileInfo) (time.Time, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || (stat.Birthtimespec.Sec == 0 && stat.Birthtimespec.Nsec == 0) {
+		return time.Time{}, false
+	}
+	return time.Unix(stat.Birthtimespec.Unix()), true
+}
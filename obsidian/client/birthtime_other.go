@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !windows
+
+package client
+
+import (
+	"io/fs"
+	"time"
+)
+
+// birthTime reports false: this platform does not expose file birth times
+func birthTime(_ string, _ fs.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
@@ -0,0 +1,16 @@
+package client
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// birthTime returns when a file was created, from its file attributes
+func birthTime(_ string, info fs.FileInfo) (time.Time, bool) {
+	attributes, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, attributes.CreationTime.Nanoseconds()), true
+}
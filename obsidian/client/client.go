@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,6 +17,9 @@ import (
 	"mcp-obsidian/obsidian/types"
 )
 
+// ErrNotFound is returned when the requested note does not exist
+var ErrNotFound = errors.New("note not found")
+
 // ObsidianClient represents a client for the Obsidian REST API
 type ObsidianClient struct {
 	config     *types.ObsidianConfig
@@ -58,15 +62,19 @@ func NewObsidianClient(config *types.ObsidianConfig) *ObsidianClient {
 	}
 }
 
-// NewObsidianClientFromEnv creates a new ObsidianClient from environment variables
-func NewObsidianClientFromEnv() (*ObsidianClient, error) {
+// Backend identifiers accepted in OBSIDIAN_BACKEND
+const (
+	BackendREST       = "rest"
+	BackendFilesystem = "filesystem"
+)
+
+// LoadConfigFromEnv builds an ObsidianConfig from environment variables without
+// validating backend-specific requirements such as the API key
+func LoadConfigFromEnv() *types.ObsidianConfig {
 	config := types.NewObsidianConfig()
 
-	// Load configuration from environment variables
 	if apiKey := os.Getenv("OBSIDIAN_API_KEY"); apiKey != "" {
 		config.APIKey = apiKey
-	} else {
-		return nil, fmt.Errorf("OBSIDIAN_API_KEY environment variable is required")
 	}
 
 	if host := os.Getenv("OBSIDIAN_HOST"); host != "" {
@@ -86,12 +94,54 @@ func NewObsidianClientFromEnv() (*ObsidianClient, error) {
 		config.VaultPath = vaultPath
 	}
 
+	if backend := os.Getenv("OBSIDIAN_BACKEND"); backend != "" {
+		config.Backend = strings.ToLower(strings.TrimSpace(backend))
+	}
+
+	if timezone := os.Getenv("OBSIDIAN_TIMEZONE"); timezone != "" {
+		config.Timezone = timezone
+	}
+
+	if weekStart := os.Getenv("OBSIDIAN_WEEK_START"); weekStart != "" {
+		config.WeekStart = weekStart
+	}
+
+	if allowlist := os.Getenv("OBSIDIAN_COMMAND_ALLOWLIST"); allowlist != "" {
+		config.CommandAllowlist = splitList(allowlist)
+	}
+
+	if denylist := os.Getenv("OBSIDIAN_COMMAND_DENYLIST"); denylist != "" {
+		config.CommandDenylist = splitList(denylist)
+	}
+
 	if useHTTPS := os.Getenv("OBSIDIAN_USE_HTTPS"); useHTTPS != "" {
 		if parsed, err := strconv.ParseBool(useHTTPS); err == nil {
 			config.UseHTTPS = parsed
 		}
 	}
 
+	return config
+}
+
+// splitList splits a comma-separated environment value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// NewObsidianClientFromEnv creates a new ObsidianClient from environment variables
+func NewObsidianClientFromEnv() (*ObsidianClient, error) {
+	config := LoadConfigFromEnv()
+
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("OBSIDIAN_API_KEY environment variable is required")
+	}
+
 	return NewObsidianClient(config), nil
 }
 
@@ -289,38 +339,18 @@ func (c *ObsidianClient) Search(query string, contextLength int) ([]types.Search
 // AppendContent appends content to a file
 func (c *ObsidianClient) AppendContent(filePath, content string) error {
 	endpoint := fmt.Sprintf("/vault/%s", url.PathEscape(filePath))
-
-	req, err := http.NewRequest("POST", c.baseURL+endpoint, strings.NewReader(content))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add headers
-	headers := c.getHeaders()
-	headers["Content-Type"] = "text/markdown"
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("append content failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	return nil
+	return c.sendMarkdown("POST", endpoint, content, "append content")
 }
 
 // PutContent creates or updates a file
 func (c *ObsidianClient) PutContent(filePath, content string) error {
 	endpoint := fmt.Sprintf("/vault/%s", url.PathEscape(filePath))
+	return c.sendMarkdown("PUT", endpoint, content, "put content")
+}
 
-	req, err := http.NewRequest("PUT", c.baseURL+endpoint, strings.NewReader(content))
+// sendMarkdown sends a markdown body to the given endpoint
+func (c *ObsidianClient) sendMarkdown(method, endpoint, content, action string) error {
+	req, err := http.NewRequest(method, c.baseURL+endpoint, strings.NewReader(content))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -340,7 +370,7 @@ func (c *ObsidianClient) PutContent(filePath, content string) error {
 
 	if resp.StatusCode >= 400 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("put content failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return fmt.Errorf("%s failed with status %d: %s", action, resp.StatusCode, string(bodyBytes))
 	}
 
 	return nil
@@ -361,7 +391,11 @@ func (c *ObsidianClient) DeleteFile(filePath string) error {
 // PatchContent patches content in a file using the correct API format
 func (c *ObsidianClient) PatchContent(filePath, operation, targetType, target, content string) error {
 	endpoint := fmt.Sprintf("/vault/%s", url.PathEscape(filePath))
+	return c.sendPatch(endpoint, operation, targetType, target, content)
+}
 
+// sendPatch sends a PATCH request targeting a heading, block or frontmatter field
+func (c *ObsidianClient) sendPatch(endpoint, operation, targetType, target, content string) error {
 	req, err := http.NewRequest("PATCH", c.baseURL+endpoint, strings.NewReader(content))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -406,103 +440,77 @@ func (c *ObsidianClient) PatchContent(filePath, operation, targetType, target, c
 
 // SearchJSON performs a complex search using JsonLogic
 func (c *ObsidianClient) SearchJSON(query map[string]interface{}) ([]types.SearchResult, error) {
-	endpoint := "/search/"
-
 	queryBytes, err := json.Marshal(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal query: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+endpoint, bytes.NewReader(queryBytes))
+	var results []types.SearchResult
+	if err := c.postSearch("application/vnd.olrapi.jsonlogic+json", queryBytes, "JSON search", &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// postSearch sends a query to the /search/ endpoint and decodes the response into out
+func (c *ObsidianClient) postSearch(contentType string, query []byte, action string, out interface{}) error {
+	req, err := http.NewRequest("POST", c.baseURL+"/search/", bytes.NewReader(query))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers
 	headers := c.getHeaders()
-	headers["Content-Type"] = "application/vnd.olrapi.jsonlogic+json"
+	headers["Content-Type"] = contentType
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("JSON search failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return fmt.Errorf("%s failed with status %d: %s", action, resp.StatusCode, string(bodyBytes))
 	}
 
-	var results []types.SearchResult
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode search results: %w", err)
 	}
 
-	return results, nil
+	return nil
 }
 
-// GetPeriodicNote gets or creates a periodic note
-func (c *ObsidianClient) GetPeriodicNote(period, date string) (*types.PeriodicNoteResponse, error) {
-	// Parse the date to get year, month, day
-	// Expected format: YYYY-MM-DD
-	parts := strings.Split(date, "-")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("invalid date format, expected YYYY-MM-DD")
-	}
-
-	year := parts[0]
-	month := parts[1]
-	day := parts[2]
-
-	// Remove leading zeros from month and day
-	month = strings.TrimLeft(month, "0")
-	day = strings.TrimLeft(day, "0")
+// GetNote gets a note with its parsed metadata using the note+json representation
+func (c *ObsidianClient) GetNote(filePath string) (*types.NoteJSON, error) {
+	endpoint := fmt.Sprintf("/vault/%s", url.PathEscape(filePath))
 
-	endpoint := fmt.Sprintf("/periodic/%s/%s/%s/%s/", period, year, month, day)
-	resp, err := c.makeRequest("GET", endpoint, nil)
+	note, err := c.getNoteJSON(endpoint)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var result types.PeriodicNoteResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode periodic note response: %w", err)
+	if note.Path == "" {
+		note.Path = filePath
 	}
 
-	return &result, nil
+	return note, nil
 }
 
-// CreatePeriodicNote creates a new periodic note
-func (c *ObsidianClient) CreatePeriodicNote(period, date, content string) (*types.PeriodicNoteResponse, error) {
-	// Parse the date to get year, month, day
-	// Expected format: YYYY-MM-DD
-	parts := strings.Split(date, "-")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("invalid date format, expected YYYY-MM-DD")
-	}
-
-	year := parts[0]
-	month := parts[1]
-	day := parts[2]
-
-	// Remove leading zeros from month and day
-	month = strings.TrimLeft(month, "0")
-	day = strings.TrimLeft(day, "0")
-
-	endpoint := fmt.Sprintf("/periodic/%s/%s/%s/%s/", period, year, month, day)
-
-	req, err := http.NewRequest("POST", c.baseURL+endpoint, strings.NewReader(content))
+// getNoteJSON requests the note+json representation from the given endpoint
+func (c *ObsidianClient) getNoteJSON(endpoint string) (*types.NoteJSON, error) {
+	req, err := http.NewRequest("GET", c.baseURL+endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers
 	headers := c.getHeaders()
-	headers["Content-Type"] = "text/markdown"
+	headers["Accept"] = "application/vnd.olrapi.note+json"
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
@@ -513,74 +521,38 @@ func (c *ObsidianClient) CreatePeriodicNote(period, date, content string) (*type
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, endpoint)
+	}
 	if resp.StatusCode >= 400 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("create periodic note failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, fmt.Errorf("get note failed with status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
-	var result types.PeriodicNoteResponse
+	var result types.NoteJSON
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode periodic note response: %w", err)
+		return nil, fmt.Errorf("failed to decode note response: %w", err)
 	}
 
 	return &result, nil
 }
 
-// GetRecentChanges gets recent changes in the vault
-// Note: This endpoint is not available in the current version of the API
-func (c *ObsidianClient) GetRecentChanges(limit, days int) (*types.RecentChangesResponse, error) {
-	return nil, fmt.Errorf("recent changes endpoint is not available in this version of the API")
-}
-
-// GetTags gets all tags in the vault
-// Note: This endpoint is not available in the current version of the API
-func (c *ObsidianClient) GetTags() (*types.TagInfo, error) {
-	return nil, fmt.Errorf("tags endpoint is not available in this version of the API")
-}
-
 // GetFrontmatter gets frontmatter from a file
 func (c *ObsidianClient) GetFrontmatter(filePath string) (*types.FrontmatterResponse, error) {
-	endpoint := fmt.Sprintf("/vault/%s", url.PathEscape(filePath))
-
-	req, err := http.NewRequest("GET", c.baseURL+endpoint, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add headers
-	headers := c.getHeaders()
-	headers["Accept"] = "application/vnd.olrapi.note+json"
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	note, err := c.GetNote(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("get frontmatter failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var result struct {
-		Frontmatter map[string]interface{} `json:"frontmatter"`
-		Tags        []string               `json:"tags"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode frontmatter response: %w", err)
+		return nil, err
 	}
 
 	return &types.FrontmatterResponse{
 		Path: filePath,
-		Data: result.Frontmatter,
+		Data: note.Frontmatter,
 	}, nil
 }
 
-// SetFrontmatter sets frontmatter for a file
-func (c *ObsidianClient) SetFrontmatter(filePath, field, value string) error {
+// SetFrontmatter sets a frontmatter field for a file. The value is sent as
+// JSON so lists, numbers, booleans and objects keep their type.
+func (c *ObsidianClient) SetFrontmatter(filePath, field string, value interface{}) error {
 	endpoint := fmt.Sprintf("/vault/%s", url.PathEscape(filePath))
 
 	// JSON encode the value
@@ -619,9 +591,3 @@ func (c *ObsidianClient) SetFrontmatter(filePath, field, value string) error {
 
 	return nil
 }
-
-// GetBlockReference gets a block reference from a file
-// Note: This endpoint is not available in the current version of the API
-func (c *ObsidianClient) GetBlockReference(filePath, blockID string) (*types.BlockReferenceResponse, error) {
-	return nil, fmt.Errorf("block reference endpoint is not available in this version of the API")
-}
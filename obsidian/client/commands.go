@@ -0,0 +1,72 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// ListCommands lists the commands available in the Obsidian command palette
+func (c *ObsidianClient) ListCommands() ([]types.Command, error) {
+	resp, err := c.makeRequest("GET", "/commands/", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Commands []types.Command `json:"commands"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Commands, nil
+}
+
+// ExecuteCommand runs a command by ID if the configured command policy allows it
+func (c *ObsidianClient) ExecuteCommand(commandID string) error {
+	if !c.CommandAllowed(commandID) {
+		return fmt.Errorf("command %s is not allowed by OBSIDIAN_COMMAND_ALLOWLIST/OBSIDIAN_COMMAND_DENYLIST", commandID)
+	}
+
+	endpoint := fmt.Sprintf("/commands/%s/", url.PathEscape(commandID))
+	resp, err := c.makeRequest("POST", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// CommandAllowed reports whether agents may execute the given command ID.
+// The denylist always wins; an empty allowlist allows everything else.
+func (c *ObsidianClient) CommandAllowed(commandID string) bool {
+	if matchesAnyPattern(c.config.CommandDenylist, commandID) {
+		return false
+	}
+
+	if len(c.config.CommandAllowlist) == 0 {
+		return true
+	}
+
+	return matchesAnyPattern(c.config.CommandAllowlist, commandID)
+}
+
+// matchesAnyPattern reports whether value matches one of the glob patterns
+func matchesAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == value {
+			return true
+		}
+		if matched, err := path.Match(pattern, value); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
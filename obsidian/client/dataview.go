@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// SearchDQL runs a Dataview TABLE query and returns one row per matching file
+func (c *ObsidianClient) SearchDQL(query string) ([]types.DQLRow, error) {
+	var results []struct {
+		Filename string          `json:"filename"`
+		Result   json.RawMessage `json:"result"`
+	}
+
+	if err := c.postSearch("application/vnd.olrapi.dataview.dql+txt", []byte(query), "Dataview search", &results); err != nil {
+		return nil, err
+	}
+
+	rows := make([]types.DQLRow, 0, len(results))
+	for _, result := range results {
+		columns, values, err := decodeOrderedObject(result.Result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode result for %s: %w", result.Filename, err)
+		}
+
+		rows = append(rows, types.DQLRow{
+			Filename: result.Filename,
+			Columns:  columns,
+			Values:   values,
+		})
+	}
+
+	return rows, nil
+}
+
+// decodeOrderedObject decodes a JSON object and also returns its keys in document
+// order, since Dataview columns are meaningful in the order the query lists them
+func decodeOrderedObject(data json.RawMessage) ([]string, map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if len(bytes.TrimSpace(data)) == 0 || bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return []string{}, values, nil
+	}
+
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	columns := make([]string, 0, len(values))
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("unexpected object key %v", token)
+		}
+		columns = append(columns, key)
+
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return columns, values, nil
+}
@@ -0,0 +1,499 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"mcp-obsidian/obsidian/frontmatter"
+	"mcp-obsidian/obsidian/patch"
+	"mcp-obsidian/obsidian/tags"
+	"mcp-obsidian/obsidian/types"
+)
+
+// FilesystemClient works directly on the markdown files of a vault on disk.
+// It mirrors the behaviour of ObsidianClient so the MCP server can run without
+// Obsidian or the Local REST API plugin, e.g. in CI or on headless servers.
+type FilesystemClient struct {
+	config *types.ObsidianConfig
+	root   string
+}
+
+// NewFilesystemClient creates a new filesystem client rooted at config.VaultPath
+func NewFilesystemClient(config *types.ObsidianConfig) (*FilesystemClient, error) {
+	if config.VaultPath == "" {
+		return nil, fmt.Errorf("OBSIDIAN_VAULT_PATH environment variable is required for the filesystem backend")
+	}
+
+	root, err := filepath.Abs(config.VaultPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve vault path: %w", err)
+	}
+
+	return &FilesystemClient{
+		config: config,
+		root:   root,
+	}, nil
+}
+
+// NewFilesystemClientFromEnv creates a new FilesystemClient from environment variables
+func NewFilesystemClientFromEnv() (*FilesystemClient, error) {
+	return NewFilesystemClient(LoadConfigFromEnv())
+}
+
+// resolve maps a vault-relative path to an absolute path inside the vault
+func (c *FilesystemClient) resolve(filePath string) (string, error) {
+	cleaned := filepath.Clean("/" + filepath.FromSlash(strings.TrimSpace(filePath)))
+	fullPath := filepath.Join(c.root, cleaned)
+
+	if !within(c.root, fullPath) {
+		return "", fmt.Errorf("path %s is outside the vault", filePath)
+	}
+
+	// A symlink inside the vault may lead out of it, so the deepest part of
+	// the path that exists must still be inside the vault once links are
+	// followed. Dangling links cannot be checked and are refused.
+	existing := fullPath
+	for existing != c.root {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	root, err := filepath.EvalSymlinks(c.root)
+	if err != nil {
+		// Without the vault directory nothing below it exists either
+		return fullPath, nil
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil || !within(root, resolved) {
+		return "", fmt.Errorf("path %s is outside the vault", filePath)
+	}
+
+	return fullPath, nil
+}
+
+// within reports whether path is dir or below it
+func within(dir, path string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// isHidden reports whether a vault entry should be hidden like Obsidian does (.obsidian, .trash, ...)
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// TestConnection checks that the vault directory exists
+func (c *FilesystemClient) TestConnection() error {
+	info, err := os.Stat(c.root)
+	if err != nil {
+		return fmt.Errorf("connection test failed: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("connection test failed: %s is not a directory", c.root)
+	}
+
+	return nil
+}
+
+// ListFilesInVault lists all files in the vault root
+func (c *FilesystemClient) ListFilesInVault() ([]types.FileInfo, error) {
+	return c.ListFilesInDir("")
+}
+
+// ListFilesInDir lists files in a specific directory
+func (c *FilesystemClient) ListFilesInDir(dirPath string) ([]types.FileInfo, error) {
+	fullPath, err := c.resolve(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(fullPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("directory not found: %s", dirPath)
+		}
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var files []types.FileInfo
+	for _, entry := range entries {
+		if isHidden(entry.Name()) {
+			continue
+		}
+
+		fileInfo := types.FileInfo{
+			Path: entry.Name(),
+			Name: entry.Name(),
+			Type: "file",
+		}
+
+		if entry.IsDir() {
+			fileInfo.Path = entry.Name() + "/"
+			fileInfo.Type = "directory"
+		}
+
+		if info, err := entry.Info(); err == nil {
+			if !entry.IsDir() {
+				fileInfo.Size = info.Size()
+			}
+			fileInfo.ModifiedTime = info.ModTime()
+			if created, ok := birthTime(filepath.Join(fullPath, entry.Name()), info); ok {
+				fileInfo.CreatedTime = created
+			}
+		}
+
+		files = append(files, fileInfo)
+	}
+
+	return files, nil
+}
+
+// GetFileContents gets the contents of a file
+func (c *FilesystemClient) GetFileContents(filePath string) (string, error) {
+	fullPath, err := c.resolve(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("file not found: %s", filePath)
+		}
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// Search performs a simple case-insensitive text search over all markdown notes
+func (c *FilesystemClient) Search(query string, contextLength int) ([]types.SearchResult, error) {
+	if query == "" {
+		return nil, fmt.Errorf("search query must not be empty")
+	}
+	// Matching the original text keeps every offset valid in it; lowercasing
+	// first would change the byte length of some characters
+	pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+
+	var results []types.SearchResult
+	err := c.walkNotes(func(relPath, fullPath string) error {
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return nil
+		}
+
+		content := string(data)
+
+		var matches []types.SearchMatch
+		for _, loc := range pattern.FindAllStringIndex(content, -1) {
+			start, end := loc[0], loc[1]
+
+			// Widen the context to whole characters
+			contextStart := max(start-contextLength, 0)
+			for contextStart > 0 && !utf8.RuneStart(content[contextStart]) {
+				contextStart--
+			}
+			contextEnd := min(end+contextLength, len(content))
+			for contextEnd < len(content) && !utf8.RuneStart(content[contextEnd]) {
+				contextEnd++
+			}
+
+			matches = append(matches, types.SearchMatch{
+				Context: content[contextStart:contextEnd],
+				MatchPosition: types.MatchPos{
+					Start: start,
+					End:   end,
+				},
+			})
+		}
+
+		if len(matches) > 0 {
+			results = append(results, types.SearchResult{
+				Filename: relPath,
+				Score:    float64(len(matches)),
+				Matches:  matches,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results, nil
+}
+
+// AppendContent appends content to a file, creating it if needed
+func (c *FilesystemClient) AppendContent(filePath, content string) error {
+	fullPath, err := c.resolve(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	file, err := os.OpenFile(fullPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return fmt.Errorf("append content failed: %w", err)
+	}
+
+	return nil
+}
+
+// PutContent creates or updates a file
+func (c *FilesystemClient) PutContent(filePath, content string) error {
+	fullPath, err := c.resolve(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("put content failed: %w", err)
+	}
+
+	return nil
+}
+
+// MoveFile moves a file or folder within the vault, creating parent folders as needed
+func (c *FilesystemClient) MoveFile(src, dst string) error {
+	srcPath, err := c.resolve(src)
+	if err != nil {
+		return err
+	}
+	dstPath, err := c.resolve(dst)
+	if err != nil {
+		return err
+	}
+
+	if srcPath == c.root || dstPath == c.root {
+		return fmt.Errorf("refusing to move the vault root")
+	}
+	if strings.HasPrefix(dstPath, srcPath+string(filepath.Separator)) {
+		return fmt.Errorf("cannot move %s into itself", src)
+	}
+
+	if _, err := os.Stat(srcPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("file not found: %s", src)
+		}
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	if _, err := os.Lstat(dstPath); err == nil {
+		return fmt.Errorf("destination already exists: %s", dst)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	if err := os.Rename(srcPath, dstPath); err != nil {
+		return fmt.Errorf("move failed: %w", err)
+	}
+
+	return nil
+}
+
+// CopyFile copies a single file byte for byte, creating parent folders as needed
+func (c *FilesystemClient) CopyFile(src, dst string) error {
+	srcPath, err := c.resolve(src)
+	if err != nil {
+		return err
+	}
+	dstPath, err := c.resolve(dst)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("file not found: %s", src)
+		}
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if _, err := os.Lstat(dstPath); err == nil {
+		return fmt.Errorf("destination already exists: %s", dst)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+	if err := os.WriteFile(dstPath, data, 0644); err != nil {
+		return fmt.Errorf("copy failed: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFile deletes a file or directory
+func (c *FilesystemClient) DeleteFile(filePath string) error {
+	fullPath, err := c.resolve(filePath)
+	if err != nil {
+		return err
+	}
+
+	if fullPath == c.root {
+		return fmt.Errorf("refusing to delete the vault root")
+	}
+
+	if _, err := os.Stat(fullPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("file not found: %s", filePath)
+		}
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if err := os.RemoveAll(fullPath); err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+
+	return nil
+}
+
+// PatchContent patches content in a file relative to a heading, block or frontmatter field
+func (c *FilesystemClient) PatchContent(filePath, operation, targetType, target, content string) error {
+	existing, err := c.GetFileContents(filePath)
+	if err != nil {
+		return err
+	}
+
+	updated, err := patch.Apply(existing, operation, targetType, target, content)
+	if err != nil {
+		return fmt.Errorf("patch content failed: %w", err)
+	}
+
+	return c.PutContent(filePath, updated)
+}
+
+// GetFrontmatter gets frontmatter from a file
+func (c *FilesystemClient) GetFrontmatter(filePath string) (*types.FrontmatterResponse, error) {
+	content, err := c.GetFileContents(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := frontmatter.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.FrontmatterResponse{
+		Path: filePath,
+		Data: data,
+	}, nil
+}
+
+// SetFrontmatter sets a frontmatter field for a file
+func (c *FilesystemClient) SetFrontmatter(filePath, field string, value interface{}) error {
+	content, err := c.GetFileContents(filePath)
+	if err != nil {
+		return err
+	}
+
+	updated, err := frontmatter.SetField(content, field, value)
+	if err != nil {
+		return fmt.Errorf("set frontmatter failed: %w", err)
+	}
+
+	return c.PutContent(filePath, updated)
+}
+
+// walkNotes calls fn for every markdown note in the vault, skipping hidden folders
+func (c *FilesystemClient) walkNotes(fn func(relPath, fullPath string) error) error {
+	return filepath.WalkDir(c.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if path != c.root && isHidden(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".md") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(c.root, path)
+		if err != nil {
+			return nil
+		}
+
+		return fn(filepath.ToSlash(relPath), path)
+	})
+}
+
+// GetNote gets a note with its parsed metadata, mirroring the note+json representation
+func (c *FilesystemClient) GetNote(filePath string) (*types.NoteJSON, error) {
+	fullPath, err := c.resolve(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("file not found: %s", filePath)
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	content, err := c.GetFileContents(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := frontmatter.Parse(content)
+	if err != nil {
+		// Obsidian still serves notes with broken frontmatter
+		data = map[string]interface{}{}
+	}
+
+	noteTags := tags.Extract(content)
+	if noteTags == nil {
+		noteTags = []string{}
+	}
+
+	stat := types.NoteStat{
+		Mtime: info.ModTime().UnixMilli(),
+		Size:  info.Size(),
+	}
+	// Where the platform or filesystem does not record birth times ctime
+	// stays 0 rather than standing in for the modification time
+	if created, ok := birthTime(fullPath, info); ok {
+		stat.Ctime = created.UnixMilli()
+	}
+
+	return &types.NoteJSON{
+		Path:        filePath,
+		Content:     content,
+		Frontmatter: data,
+		Tags:        noteTags,
+		Stat:        stat,
+	}, nil
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+func TestFilesystemSearchNonASCII(t *testing.T) {
+	dir := t.TempDir()
+	// Ⱥ is two bytes but lowercases to the three-byte ⱥ
+	content := "ȺȺȺȺȺȺȺȺȺȺ x ⱥ"
+	if err := os.WriteFile(filepath.Join(dir, "note.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewFilesystemClient(&types.ObsidianConfig{VaultPath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query         string
+		contextLength int
+		want          []string // matched text of each match
+		contexts      []string
+	}{
+		{"x", 0, []string{"x"}, []string{"x"}},
+		{"x", 1, []string{"x"}, []string{" x "}},
+		{"X", 3, []string{"x"}, []string{"Ⱥ x ⱥ"}},
+		{"ⱥ", 0, []string{"Ⱥ", "Ⱥ", "Ⱥ", "Ⱥ", "Ⱥ", "Ⱥ", "Ⱥ", "Ⱥ", "Ⱥ", "Ⱥ", "ⱥ"}, nil},
+	}
+
+	for _, tt := range tests {
+		results, err := c.Search(tt.query, tt.contextLength)
+		if err != nil {
+			t.Fatalf("Search(%q) returned error: %v", tt.query, err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Search(%q) returned %d results, want 1", tt.query, len(results))
+		}
+
+		matches := results[0].Matches
+		if len(matches) != len(tt.want) {
+			t.Fatalf("Search(%q) found %d matches, want %d", tt.query, len(matches), len(tt.want))
+		}
+		for i, match := range matches {
+			if got := content[match.MatchPosition.Start:match.MatchPosition.End]; got != tt.want[i] {
+				t.Errorf("Search(%q) match %d covers %q, want %q", tt.query, i, got, tt.want[i])
+			}
+			if tt.contexts != nil && match.Context != tt.contexts[i] {
+				t.Errorf("Search(%q, %d) context = %q, want %q", tt.query, tt.contextLength, match.Context, tt.contexts[i])
+			}
+		}
+	}
+}
+
+func TestFilesystemCreatedTime(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "note.md")
+	if err := os.WriteFile(file, []byte("text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// An old modification time must not be reported as the creation time
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(file, old, old); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewFilesystemClient(&types.ObsidianConfig{VaultPath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, recorded := birthTime(file, info)
+
+	note, err := c.GetNote("note.md")
+	if err != nil {
+		t.Fatalf("GetNote returned error: %v", err)
+	}
+	files, err := c.ListFilesInDir("")
+	if err != nil || len(files) != 1 {
+		t.Fatalf("ListFilesInDir = %+v, %v, want note.md", files, err)
+	}
+
+	if !recorded {
+		if note.Stat.Ctime != 0 || !files[0].CreatedTime.IsZero() {
+			t.Errorf("ctime = %d and createdTime = %v without a birth time, want them left out", note.Stat.Ctime, files[0].CreatedTime)
+		}
+		return
+	}
+	if created.Before(old.Add(time.Hour)) {
+		t.Errorf("birth time %v follows the modification time, want the creation time", created)
+	}
+	if note.Stat.Ctime != created.UnixMilli() || !files[0].CreatedTime.Equal(created) {
+		t.Errorf("ctime = %d and createdTime = %v, want %v", note.Stat.Ctime, files[0].CreatedTime, created)
+	}
+}
+
+func TestFilesystemSymlinks(t *testing.T) {
+	base := t.TempDir()
+	vault := filepath.Join(base, "vault")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(vault, "sub"), outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.md"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vault, "sub", "note.md"), []byte("note"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		filepath.Join(vault, "out"):      outside,
+		filepath.Join(vault, "inside"):   filepath.Join(vault, "sub"),
+		filepath.Join(vault, "dangling"): filepath.Join(outside, "missing.md"),
+		filepath.Join(base, "link"):      vault,
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	// The vault itself is reached through a symlink
+	c, err := NewFilesystemClient(&types.ObsidianConfig{VaultPath: filepath.Join(base, "link")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"sub/note.md", true},
+		{"inside/note.md", true},
+		{"new/folder/note.md", true},
+		{"out/secret.md", false},
+		{"out/new/note.md", false},
+		{"dangling", false},
+	}
+
+	for _, tt := range tests {
+		_, err := c.resolve(tt.path)
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("resolve(%q) error = %v, want allowed %v", tt.path, err, tt.allowed)
+		}
+	}
+
+	if _, err := c.GetFileContents("out/secret.md"); err == nil {
+		t.Errorf("GetFileContents read a file outside the vault through a symlink")
+	}
+	if err := c.PutContent("dangling", "x"); err == nil {
+		t.Errorf("PutContent wrote through a dangling symlink")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "missing.md")); err == nil {
+		t.Errorf("PutContent created a file outside the vault")
+	}
+}
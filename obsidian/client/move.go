@@ -0,0 +1,94 @@
+package client
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MoveFile moves a note, or a folder of notes, to a new path. The Local REST
+// API has no rename endpoint, so each note is written to its new path and then
+// deleted from the old one. Contents travel as text, so attachments have to be
+// moved with the filesystem backend.
+func (c *ObsidianClient) MoveFile(src, dst string) error {
+	src = strings.Trim(src, "/")
+	dst = strings.Trim(dst, "/")
+
+	if IsTextFile(src) {
+		return c.moveNote(src, dst)
+	}
+
+	files, err := c.listFilesRecursive(src)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", src, err)
+	}
+	for _, file := range files {
+		if !IsTextFile(file) {
+			return fmt.Errorf("cannot move %s: the REST API backend can only move notes, use the filesystem backend to move attachments", file)
+		}
+	}
+
+	for _, file := range files {
+		if err := c.moveNote(file, dst+strings.TrimPrefix(file, src)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveNote writes a note to dst and deletes it from src
+func (c *ObsidianClient) moveNote(src, dst string) error {
+	if _, err := c.GetFileContents(dst); err == nil {
+		return fmt.Errorf("destination already exists: %s", dst)
+	}
+
+	content, err := c.GetFileContents(src)
+	if err != nil {
+		return err
+	}
+
+	if err := c.PutContent(dst, content); err != nil {
+		return err
+	}
+
+	return c.DeleteFile(src)
+}
+
+// listFilesRecursive lists every file below dirPath with vault-relative paths
+func (c *ObsidianClient) listFilesRecursive(dirPath string) ([]string, error) {
+	entries, err := c.ListFilesInDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Path, "/")
+		if !strings.HasPrefix(name, dirPath+"/") {
+			name = path.Join(dirPath, name)
+		}
+
+		if entry.Type == "directory" {
+			children, err := c.listFilesRecursive(name)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, children...)
+			continue
+		}
+		files = append(files, name)
+	}
+
+	return files, nil
+}
+
+// IsTextFile reports whether a vault file can be copied through the REST API as text.
+// Other files are binary attachments that need a CopyBackend or MoveBackend.
+func IsTextFile(filePath string) bool {
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".md", ".canvas":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// OpenFile opens a note in the Obsidian user interface, optionally in a new leaf.
+// Obsidian creates the note if it does not exist yet.
+func (c *ObsidianClient) OpenFile(filePath string, newLeaf bool) error {
+	endpoint := fmt.Sprintf("/open/%s", url.PathEscape(filePath))
+	if newLeaf {
+		endpoint += "?newLeaf=true"
+	}
+
+	resp, err := c.makeRequest("POST", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// PeriodicPeriods lists the periods supported by the Periodic Notes plugin
+var PeriodicPeriods = []string{"daily", "weekly", "monthly", "quarterly", "yearly"}
+
+// periodicEndpoint builds the endpoint for a periodic note. An empty date
+// addresses the note for the current period; otherwise date must be YYYY-MM-DD
+// and may be any day inside the period.
+func periodicEndpoint(period, date string) (string, error) {
+	valid := false
+	for _, p := range PeriodicPeriods {
+		if period == p {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", fmt.Errorf("invalid period: %s. Must be one of: daily, weekly, monthly, quarterly, yearly", period)
+	}
+
+	if date == "" {
+		return fmt.Sprintf("/periodic/%s/", period), nil
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", fmt.Errorf("invalid date format, expected YYYY-MM-DD: %s", date)
+	}
+
+	return fmt.Sprintf("/periodic/%s/%d/%d/%d/", period, day.Year(), int(day.Month()), day.Day()), nil
+}
+
+// GetPeriodicNote gets a periodic note; an empty date means the current period.
+// A note that does not exist yet is returned with Exists false.
+func (c *ObsidianClient) GetPeriodicNote(period, date string) (*types.PeriodicNoteResponse, error) {
+	endpoint, err := periodicEndpoint(period, date)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := c.getNoteJSON(endpoint)
+	if errors.Is(err, ErrNotFound) {
+		return &types.PeriodicNoteResponse{Exists: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.PeriodicNoteResponse{
+		Path:    note.Path,
+		Content: note.Content,
+		Exists:  true,
+	}, nil
+}
+
+// CreatePeriodicNote appends content to a periodic note, creating it if needed
+func (c *ObsidianClient) CreatePeriodicNote(period, date, content string) (*types.PeriodicNoteResponse, error) {
+	endpoint, err := periodicEndpoint(period, date)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.sendMarkdown("POST", endpoint, content, "create periodic note"); err != nil {
+		return nil, err
+	}
+
+	return c.GetPeriodicNote(period, date)
+}
+
+// PutPeriodicNote replaces the content of a periodic note, creating it if needed
+func (c *ObsidianClient) PutPeriodicNote(period, date, content string) error {
+	endpoint, err := periodicEndpoint(period, date)
+	if err != nil {
+		return err
+	}
+
+	return c.sendMarkdown("PUT", endpoint, content, "put periodic note")
+}
+
+// PatchPeriodicNote patches a periodic note relative to a heading, block or frontmatter field
+func (c *ObsidianClient) PatchPeriodicNote(period, date, operation, targetType, target, content string) error {
+	endpoint, err := periodicEndpoint(period, date)
+	if err != nil {
+		return err
+	}
+
+	return c.sendPatch(endpoint, operation, targetType, target, content)
+}
+
+// DeletePeriodicNote deletes a periodic note
+func (c *ObsidianClient) DeletePeriodicNote(period, date string) error {
+	endpoint, err := periodicEndpoint(period, date)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.makeRequest("DELETE", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,304 @@
+// Package dates resolves natural-language and calendar date expressions such as
+// "today", "last monday", "2026-W14" or "2026-Q3" into concrete date spans.
+package dates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Granularity is the calendar unit an expression refers to
+type Granularity string
+
+// Supported granularities
+const (
+	Day     Granularity = "day"
+	Week    Granularity = "week"
+	Month   Granularity = "month"
+	Quarter Granularity = "quarter"
+	Year    Granularity = "year"
+)
+
+// DateLayout is the YYYY-MM-DD layout used by periodic note endpoints
+const DateLayout = "2006-01-02"
+
+// Options controls how expressions are resolved
+type Options struct {
+	Location  *time.Location // Time zone "today" is evaluated in; nil means UTC
+	WeekStart time.Weekday   // First day of "this week", "last week", ...; ISO weeks always start on Monday
+}
+
+// Span is a resolved date range. Start is midnight of the first day and End is
+// midnight of the day after the last one, both in the resolving location.
+type Span struct {
+	Start       time.Time
+	End         time.Time
+	Granularity Granularity
+}
+
+// Date returns the first day of the span in YYYY-MM-DD format
+func (s Span) Date() string {
+	return s.Start.Format(DateLayout)
+}
+
+// LastDay returns the last day contained in the span
+func (s Span) LastDay() time.Time {
+	return s.End.AddDate(0, 0, -1)
+}
+
+// Days returns every day in the span, in order
+func (s Span) Days() []time.Time {
+	var days []time.Time
+	for day := s.Start; day.Before(s.End); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// NewOptions builds Options from an IANA time zone name and a weekday name.
+// Empty values fall back to UTC and Monday.
+func NewOptions(timezone, weekStart string) (Options, error) {
+	opts := Options{Location: time.UTC, WeekStart: time.Monday}
+
+	if timezone = strings.TrimSpace(timezone); timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return opts, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+		}
+		opts.Location = loc
+	}
+
+	if weekStart = strings.TrimSpace(weekStart); weekStart != "" {
+		weekday, ok := parseWeekday(strings.ToLower(weekStart))
+		if !ok {
+			return opts, fmt.Errorf("invalid week start %q", weekStart)
+		}
+		opts.WeekStart = weekday
+	}
+
+	return opts, nil
+}
+
+var (
+	isoDatePattern  = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	isoWeekPattern  = regexp.MustCompile(`^(\d{4})-w(\d{1,2})$`)
+	quarterPattern  = regexp.MustCompile(`^(\d{4})-q([1-4])$`)
+	monthPattern    = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	yearPattern     = regexp.MustCompile(`^(\d{4})$`)
+	agoPattern      = regexp.MustCompile(`^(\d+) (day|week|month|quarter|year)s? ago$`)
+	inPattern       = regexp.MustCompile(`^in (\d+) (day|week|month|quarter|year)s?$`)
+	relativePattern = regexp.MustCompile(`^(this|last|next) (week|month|quarter|year)$`)
+	weekdayPattern  = regexp.MustCompile(`^(?:(this|last|next) )?([a-z]+)$`)
+)
+
+// Resolve turns an expression into a Span relative to now. Supported forms:
+//
+//	today, yesterday, tomorrow
+//	monday, this friday, last monday, next sunday
+//	3 days ago, in 2 weeks
+//	this week, last month, next quarter, this year
+//	2026-10-17, 2026-W14 (ISO week), 2026-Q3, 2026-10, 2026
+func Resolve(expr string, now time.Time, opts Options) (Span, error) {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	normalized := strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+	today := startOfDay(now.In(loc))
+
+	switch normalized {
+	case "":
+		return Span{}, fmt.Errorf("date expression must not be empty")
+	case "today", "now":
+		return daySpan(today), nil
+	case "yesterday":
+		return daySpan(today.AddDate(0, 0, -1)), nil
+	case "tomorrow":
+		return daySpan(today.AddDate(0, 0, 1)), nil
+	}
+
+	if m := isoDatePattern.FindStringSubmatch(normalized); m != nil {
+		day, err := time.ParseInLocation(DateLayout, normalized, loc)
+		if err != nil {
+			return Span{}, fmt.Errorf("invalid date %q", expr)
+		}
+		return daySpan(day), nil
+	}
+
+	if m := isoWeekPattern.FindStringSubmatch(normalized); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		start, err := isoWeekStart(year, week, loc)
+		if err != nil {
+			return Span{}, err
+		}
+		return Span{Start: start, End: start.AddDate(0, 0, 7), Granularity: Week}, nil
+	}
+
+	if m := quarterPattern.FindStringSubmatch(normalized); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		return quarterSpan(time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, loc)), nil
+	}
+
+	if m := monthPattern.FindStringSubmatch(normalized); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return Span{}, fmt.Errorf("invalid month in %q", expr)
+		}
+		return monthSpan(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)), nil
+	}
+
+	if m := yearPattern.FindStringSubmatch(normalized); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		return yearSpan(time.Date(year, time.January, 1, 0, 0, 0, 0, loc)), nil
+	}
+
+	if m := agoPattern.FindStringSubmatch(normalized); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return shift(today, Granularity(m[2]), -n, opts), nil
+	}
+
+	if m := inPattern.FindStringSubmatch(normalized); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return shift(today, Granularity(m[2]), n, opts), nil
+	}
+
+	if m := relativePattern.FindStringSubmatch(normalized); m != nil {
+		offset := map[string]int{"this": 0, "last": -1, "next": 1}[m[1]]
+		return shift(today, Granularity(m[2]), offset, opts), nil
+	}
+
+	if m := weekdayPattern.FindStringSubmatch(normalized); m != nil {
+		if weekday, ok := parseWeekday(m[2]); ok {
+			return daySpan(resolveWeekday(today, m[1], weekday, opts.WeekStart)), nil
+		}
+	}
+
+	return Span{}, fmt.Errorf("unrecognised date expression %q", expr)
+}
+
+// SpanFor returns the span of the given granularity that contains day
+func SpanFor(day time.Time, granularity Granularity, opts Options) (Span, error) {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	day = startOfDay(day.In(loc))
+
+	switch granularity {
+	case Day:
+		return daySpan(day), nil
+	case Week:
+		return weekSpan(day, opts.WeekStart), nil
+	case Month:
+		return monthSpan(day), nil
+	case Quarter:
+		return quarterSpan(day), nil
+	case Year:
+		return yearSpan(day), nil
+	default:
+		return Span{}, fmt.Errorf("unknown granularity %q", granularity)
+	}
+}
+
+// shift returns the span of the given unit that is n units away from today
+func shift(today time.Time, unit Granularity, n int, opts Options) Span {
+	switch unit {
+	case Week:
+		return weekSpan(today.AddDate(0, 0, 7*n), opts.WeekStart)
+	case Month:
+		return monthSpan(firstOfMonth(today).AddDate(0, n, 0))
+	case Quarter:
+		return quarterSpan(firstOfMonth(today).AddDate(0, 3*n, 0))
+	case Year:
+		return yearSpan(time.Date(today.Year()+n, time.January, 1, 0, 0, 0, 0, today.Location()))
+	default:
+		return daySpan(today.AddDate(0, 0, n))
+	}
+}
+
+// resolveWeekday finds a weekday relative to today. "last" and "next" are the
+// closest matching day strictly before or after today; "this" and no qualifier
+// pick the matching day in the current week.
+func resolveWeekday(today time.Time, qualifier string, weekday, weekStart time.Weekday) time.Time {
+	switch qualifier {
+	case "last":
+		diff := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if diff == 0 {
+			diff = 7
+		}
+		return today.AddDate(0, 0, -diff)
+	case "next":
+		diff := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if diff == 0 {
+			diff = 7
+		}
+		return today.AddDate(0, 0, diff)
+	default:
+		start := weekSpan(today, weekStart).Start
+		return start.AddDate(0, 0, (int(weekday)-int(weekStart)+7)%7)
+	}
+}
+
+// isoWeekStart returns the Monday of ISO week `week` of `year`
+func isoWeekStart(year, week int, loc *time.Location) (time.Time, error) {
+	// January 4th is always in ISO week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	weekOneMonday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+
+	start := weekOneMonday.AddDate(0, 0, 7*(week-1))
+	if isoYear, isoWeek := start.ISOWeek(); week < 1 || isoYear != year || isoWeek != week {
+		return time.Time{}, fmt.Errorf("week %d does not exist in ISO year %d", week, year)
+	}
+
+	return start, nil
+}
+
+// parseWeekday parses full or three-letter English weekday names
+func parseWeekday(name string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		full := strings.ToLower(weekday.String())
+		if name == full || name == full[:3] {
+			return weekday, true
+		}
+	}
+	return time.Sunday, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func daySpan(day time.Time) Span {
+	return Span{Start: day, End: day.AddDate(0, 0, 1), Granularity: Day}
+}
+
+func weekSpan(day time.Time, weekStart time.Weekday) Span {
+	start := day.AddDate(0, 0, -((int(day.Weekday()) - int(weekStart) + 7) % 7))
+	return Span{Start: start, End: start.AddDate(0, 0, 7), Granularity: Week}
+}
+
+func monthSpan(day time.Time) Span {
+	start := firstOfMonth(day)
+	return Span{Start: start, End: start.AddDate(0, 1, 0), Granularity: Month}
+}
+
+func quarterSpan(day time.Time) Span {
+	start := time.Date(day.Year(), time.Month((int(day.Month())-1)/3*3+1), 1, 0, 0, 0, 0, day.Location())
+	return Span{Start: start, End: start.AddDate(0, 3, 0), Granularity: Quarter}
+}
+
+func yearSpan(day time.Time) Span {
+	start := time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, day.Location())
+	return Span{Start: start, End: start.AddDate(1, 0, 0), Granularity: Year}
+}
@@ -0,0 +1,162 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	// Saturday 17 October 2026, late evening in UTC
+	now := time.Date(2026, time.October, 17, 22, 30, 0, 0, time.UTC)
+	monday := Options{Location: time.UTC, WeekStart: time.Monday}
+	sunday := Options{Location: time.UTC, WeekStart: time.Sunday}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		expr        string
+		opts        Options
+		start       string
+		end         string
+		granularity Granularity
+	}{
+		{"today", "today", monday, "2026-10-17", "2026-10-18", Day},
+		{"today is case and space insensitive", "  Today ", monday, "2026-10-17", "2026-10-18", Day},
+		{"yesterday", "yesterday", monday, "2026-10-16", "2026-10-17", Day},
+		{"tomorrow", "tomorrow", monday, "2026-10-18", "2026-10-19", Day},
+		{"today in a later time zone", "today", Options{Location: tokyo, WeekStart: time.Monday}, "2026-10-18", "2026-10-19", Day},
+		{"last monday", "last monday", monday, "2026-10-12", "2026-10-13", Day},
+		{"last saturday skips today", "last saturday", monday, "2026-10-10", "2026-10-11", Day},
+		{"next saturday skips today", "next saturday", monday, "2026-10-24", "2026-10-25", Day},
+		{"next mon", "next mon", monday, "2026-10-19", "2026-10-20", Day},
+		{"bare weekday in monday week", "sunday", monday, "2026-10-18", "2026-10-19", Day},
+		{"bare weekday in sunday week", "sunday", sunday, "2026-10-11", "2026-10-12", Day},
+		{"this friday", "this friday", monday, "2026-10-16", "2026-10-17", Day},
+		{"days ago", "3 days ago", monday, "2026-10-14", "2026-10-15", Day},
+		{"in days", "in 1 day", monday, "2026-10-18", "2026-10-19", Day},
+		{"this week monday start", "this week", monday, "2026-10-12", "2026-10-19", Week},
+		{"this week sunday start", "this week", sunday, "2026-10-11", "2026-10-18", Week},
+		{"last week", "last week", monday, "2026-10-05", "2026-10-12", Week},
+		{"2 weeks ago", "2 weeks ago", monday, "2026-09-28", "2026-10-05", Week},
+		{"this month", "this month", monday, "2026-10-01", "2026-11-01", Month},
+		{"last month", "last month", monday, "2026-09-01", "2026-10-01", Month},
+		{"next quarter crosses year", "next quarter", monday, "2027-01-01", "2027-04-01", Quarter},
+		{"last year", "last year", monday, "2025-01-01", "2026-01-01", Year},
+		{"iso date", "2026-02-28", monday, "2026-02-28", "2026-03-01", Day},
+		{"iso week", "2026-W14", monday, "2026-03-30", "2026-04-06", Week},
+		{"iso week ignores week start", "2026-w14", sunday, "2026-03-30", "2026-04-06", Week},
+		{"iso week 1 starts in previous year", "2026-W01", monday, "2025-12-29", "2026-01-05", Week},
+		{"iso week 53", "2026-W53", monday, "2026-12-28", "2027-01-04", Week},
+		{"quarter", "2026-Q3", monday, "2026-07-01", "2026-10-01", Quarter},
+		{"month", "2026-10", monday, "2026-10-01", "2026-11-01", Month},
+		{"year", "2026", monday, "2026-01-01", "2027-01-01", Year},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span, err := Resolve(tt.expr, now, tt.opts)
+			if err != nil {
+				t.Fatalf("Resolve(%q) returned error: %v", tt.expr, err)
+			}
+
+			if got := span.Start.Format(DateLayout); got != tt.start {
+				t.Errorf("Resolve(%q) start = %s, want %s", tt.expr, got, tt.start)
+			}
+			if got := span.End.Format(DateLayout); got != tt.end {
+				t.Errorf("Resolve(%q) end = %s, want %s", tt.expr, got, tt.end)
+			}
+			if span.Granularity != tt.granularity {
+				t.Errorf("Resolve(%q) granularity = %s, want %s", tt.expr, span.Granularity, tt.granularity)
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	now := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+	opts := Options{Location: time.UTC, WeekStart: time.Monday}
+
+	tests := []string{
+		"",
+		"someday",
+		"2026-02-30",
+		"2026-13",
+		"2025-W53",
+		"2026-W00",
+		"2026-Q5",
+		"last fortnight",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if span, err := Resolve(expr, now, opts); err == nil {
+				t.Errorf("Resolve(%q) = %s, want error", expr, span.Date())
+			}
+		})
+	}
+}
+
+func TestSpanFor(t *testing.T) {
+	day := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		granularity Granularity
+		weekStart   time.Weekday
+		start       string
+		end         string
+	}{
+		{Day, time.Monday, "2026-10-17", "2026-10-18"},
+		{Week, time.Monday, "2026-10-12", "2026-10-19"},
+		{Week, time.Saturday, "2026-10-17", "2026-10-24"},
+		{Month, time.Monday, "2026-10-01", "2026-11-01"},
+		{Quarter, time.Monday, "2026-10-01", "2027-01-01"},
+		{Year, time.Monday, "2026-01-01", "2027-01-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.granularity)+"/"+tt.weekStart.String(), func(t *testing.T) {
+			span, err := SpanFor(day, tt.granularity, Options{Location: time.UTC, WeekStart: tt.weekStart})
+			if err != nil {
+				t.Fatalf("SpanFor returned error: %v", err)
+			}
+
+			if got := span.Start.Format(DateLayout); got != tt.start {
+				t.Errorf("start = %s, want %s", got, tt.start)
+			}
+			if got := span.End.Format(DateLayout); got != tt.end {
+				t.Errorf("end = %s, want %s", got, tt.end)
+			}
+		})
+	}
+}
+
+func TestNewOptions(t *testing.T) {
+	tests := []struct {
+		timezone  string
+		weekStart string
+		wantErr   bool
+		wantStart time.Weekday
+	}{
+		{"", "", false, time.Monday},
+		{"Europe/Berlin", "sunday", false, time.Sunday},
+		{"UTC", "Sat", false, time.Saturday},
+		{"Mars/Olympus", "", true, time.Monday},
+		{"", "someday", true, time.Monday},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone+"/"+tt.weekStart, func(t *testing.T) {
+			opts, err := NewOptions(tt.timezone, tt.weekStart)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewOptions(%q, %q) error = %v, wantErr %t", tt.timezone, tt.weekStart, err, tt.wantErr)
+			}
+			if !tt.wantErr && opts.WeekStart != tt.wantStart {
+				t.Errorf("NewOptions(%q, %q) week start = %s, want %s", tt.timezone, tt.weekStart, opts.WeekStart, tt.wantStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package frontmatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is a note whose frontmatter is held as lines, so that an edit
+// rewrites only the lines of the field it changes and every other line,
+// blank lines and comments included, is kept exactly as written
+type document struct {
+	open, close string   // delimiter lines as written, with their line endings
+	lines       []string // YAML lines, each with its line ending
+	body        string
+	newline     string
+	found       bool // whether the note already had frontmatter
+	mapping     *yaml.Node
+}
+
+// parseDocument splits a note into a document. A note without frontmatter
+// gets an empty block that is written out once a field is set.
+func parseDocument(content string) (*document, error) {
+	newline := lineEnding(content)
+	open, raw, closing, body, found := splitBlock(content)
+	if !found {
+		open, closing, body = delimiter+newline, delimiter+newline, content
+	}
+
+	doc := &document{open: open, close: closing, body: body, newline: newline, found: found}
+	if raw != "" {
+		// raw ends with a line ending, so the last element is empty
+		lines := strings.SplitAfter(raw, "\n")
+		doc.lines = lines[:len(lines)-1]
+	}
+	return doc, doc.parse()
+}
+
+// String returns the note with its current frontmatter
+func (d *document) String() string {
+	return d.open + strings.Join(d.lines, "") + d.close + d.body
+}
+
+// parse decodes the lines into the mapping that edits are located with
+func (d *document) parse() error {
+	mapping, err := parseMapping(strings.ReplaceAll(strings.Join(d.lines, ""), "\r\n", "\n"))
+	if err != nil {
+		return err
+	}
+
+	// The fields of a flow mapping share lines, so it is written out in
+	// block style before it is edited
+	if mapping.Style&yaml.FlowStyle != 0 && len(mapping.Content) > 0 {
+		mapping.Style = 0
+		lines, err := d.encode(mapping)
+		if err != nil {
+			return err
+		}
+		d.lines = lines
+		return d.parse()
+	}
+
+	d.mapping = mapping
+	return nil
+}
+
+// span returns the lines [start, end) of the field whose key is at index i
+// of the mapping: from its key up to the next key, less trailing blank lines
+// and unindented comments, which belong to what follows
+func (d *document) span(i int) (int, int) {
+	start := d.mapping.Content[i].Line - 1
+	end := len(d.lines)
+	if i+2 < len(d.mapping.Content) {
+		end = d.mapping.Content[i+2].Line - 1
+	}
+
+	for end > start+1 {
+		line := strings.TrimRight(d.lines[end-1], "\r\n")
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		end--
+	}
+	return start, end
+}
+
+// set replaces the value of a field, or adds the field after the last one
+// and before any comments that close the block
+func (d *document) set(field string, value *yaml.Node) error {
+	index := fieldIndex(d.mapping, field)
+	if index == -1 {
+		at := len(d.lines)
+		if n := len(d.mapping.Content); n > 0 {
+			_, at = d.span(n - 2)
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: field}
+		return d.replace(at, at, key, value)
+	}
+
+	// The key keeps its style and line comment; its head comment stays on
+	// the lines above, which are not rewritten
+	key := *d.mapping.Content[index]
+	key.HeadComment, key.FootComment = "", ""
+	start, end := d.span(index)
+	return d.replace(start, end, &key, value)
+}
+
+// delete removes the field whose key is at index i of the mapping, together
+// with the comment lines directly above it
+func (d *document) delete(i int) error {
+	start, end := d.span(i)
+	if d.mapping.Content[i].HeadComment != "" {
+		for start > 0 && strings.HasPrefix(d.lines[start-1], "#") {
+			start--
+		}
+	}
+
+	d.lines = append(d.lines[:start:start], d.lines[end:]...)
+	return d.parse()
+}
+
+// replace swaps lines [start, end) for the encoded field key: value
+func (d *document) replace(start, end int, key, value *yaml.Node) error {
+	entry := *value
+	entry.FootComment = ""
+	lines, err := d.encode(&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{key, &entry}})
+	if err != nil {
+		return err
+	}
+
+	// Lists written flush with their key stay that way
+	if indent, compact := d.layout(); compact && entry.Kind == yaml.SequenceNode && entry.Style&yaml.FlowStyle == 0 {
+		for i := 1; i < len(lines); i++ {
+			lines[i] = strings.TrimPrefix(lines[i], strings.Repeat(" ", indent))
+		}
+	}
+
+	d.lines = append(append(append([]string{}, d.lines[:start]...), lines...), d.lines[end:]...)
+	return d.parse()
+}
+
+// encode writes a mapping node as lines in the block's layout and line endings
+func (d *document) encode(mapping *yaml.Node) ([]string, error) {
+	indent, _ := d.layout()
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(mapping); err != nil {
+		return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+
+	lines := strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\n") + d.newline
+	}
+	return lines, nil
+}
+
+// layout returns the indentation of the block's nested lines, two spaces when
+// it has none, and whether its lists are written flush with their keys
+func (d *document) layout() (int, bool) {
+	indent, compact := 0, false
+	for _, line := range d.lines {
+		text := strings.TrimRight(line, "\r\n")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if n := len(text) - len(trimmed); n > 0 && (indent == 0 || n < indent) {
+			indent = n
+		}
+		if trimmed == text && (trimmed == "-" || strings.HasPrefix(trimmed, "- ")) {
+			compact = true
+		}
+	}
+
+	if indent == 0 {
+		indent = 2
+	}
+	return indent, compact
+}
@@ -0,0 +1,444 @@
+package frontmatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// delimiter is the line that opens and closes a YAML frontmatter block
+const delimiter = "---"
+
+// Split separates a leading YAML frontmatter block from the rest of the note.
+// It returns the raw YAML with "\n" line endings, the remaining body exactly
+// as written and whether frontmatter was found.
+func Split(content string) (string, string, bool) {
+	_, raw, _, body, found := splitBlock(content)
+	if !found {
+		return "", content, false
+	}
+	return strings.ReplaceAll(raw, "\r\n", "\n"), body, true
+}
+
+// splitBlock is Split keeping the opening delimiter line, the YAML and the
+// closing delimiter line exactly as written, line endings included
+func splitBlock(content string) (string, string, string, string, bool) {
+	first, rest, found := strings.Cut(content, "\n")
+	if !found || strings.TrimSuffix(first, "\r") != delimiter {
+		return "", "", "", content, false
+	}
+
+	offset := 0
+	for offset <= len(rest) {
+		end := strings.Index(rest[offset:], "\n")
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end+1]
+		}
+
+		if trimmed := strings.TrimRight(line, " \t\r\n"); trimmed == delimiter || trimmed == "..." {
+			return first + "\n", rest[:offset], line, rest[offset+len(line):], true
+		}
+
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+
+	return "", "", "", content, false
+}
+
+// lineEnding returns the line ending of the first line of content, "\r\n" or "\n"
+func lineEnding(content string) string {
+	if end := strings.Index(content, "\n"); end > 0 && content[end-1] == '\r' {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// Parse decodes the frontmatter of a note into a map. Notes without
+// frontmatter yield an empty map.
+func Parse(content string) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+
+	raw, _, ok := Split(content)
+	if !ok || strings.TrimSpace(raw) == "" {
+		return data, nil
+	}
+
+	mapping, err := parseMapping(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := stringDates(mapping, map[*yaml.Node]*yaml.Node{}).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+
+	return data, nil
+}
+
+// Scalar returns the text of a top-level scalar field as written, so that
+// dates and numbers such as 1.10 keep their form. ok is false when the field
+// is missing, null or not a scalar.
+func Scalar(content, field string) (string, bool, error) {
+	raw, _, found := Split(content)
+	if !found {
+		return "", false, nil
+	}
+
+	mapping, err := parseMapping(raw)
+	if err != nil {
+		return "", false, err
+	}
+
+	index := fieldIndex(mapping, field)
+	if index == -1 {
+		return "", false, nil
+	}
+	value := mapping.Content[index+1]
+	if value.Kind != yaml.ScalarNode || value.ShortTag() == "!!null" {
+		return "", false, nil
+	}
+	return value.Value, true, nil
+}
+
+// decodeNode decodes a node like yaml.Node.Decode, except that dates stay
+// the strings they were written as rather than becoming time.Time values
+func decodeNode(node *yaml.Node) (interface{}, error) {
+	var value interface{}
+	err := stringDates(node, map[*yaml.Node]*yaml.Node{}).Decode(&value)
+	return value, err
+}
+
+// stringDates returns a copy of a node tree in which timestamp scalars are
+// tagged as strings; copies maps visited nodes to their copies for aliases
+func stringDates(node *yaml.Node, copies map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if copied, ok := copies[node]; ok {
+		return copied
+	}
+
+	copied := *node
+	copies[node] = &copied
+	if copied.Kind == yaml.ScalarNode && copied.ShortTag() == "!!timestamp" {
+		copied.Tag = "!!str"
+	}
+	if copied.Alias != nil {
+		copied.Alias = stringDates(copied.Alias, copies)
+	}
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = stringDates(child, copies)
+	}
+	return &copied
+}
+
+// SetField sets a single frontmatter field and returns the updated note.
+// Frontmatter is created when missing. Only the lines of the field are
+// rewritten, so other fields, comments, blank lines and indentation are kept
+// as written.
+func SetField(content, field string, value interface{}) (string, error) {
+	return SetFields(content, []Field{{Key: field, Value: value}})
+}
+
+// SetFields sets several frontmatter fields in order and returns the updated
+// note; only Key and Value of each field are used. New fields are added
+// after the existing ones in the order given, ahead of any closing comments.
+func SetFields(content string, fields []Field) (string, error) {
+	doc, err := parseDocument(content)
+	if err != nil {
+		return "", err
+	}
+
+	for _, field := range fields {
+		valueNode, err := encodeValue(field.Value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode value for %s: %w", field.Key, err)
+		}
+
+		if index := fieldIndex(doc.mapping, field.Key); index != -1 {
+			// Keep any comment attached to the old value
+			valueNode.LineComment = doc.mapping.Content[index+1].LineComment
+		}
+		if err := doc.set(field.Key, valueNode); err != nil {
+			return "", err
+		}
+	}
+
+	return doc.String(), nil
+}
+
+// encodeValue encodes a value as a YAML node. Strings that read as dates,
+// such as 2026-01-01, are written as plain scalars the way Obsidian writes
+// them rather than quoted, so date properties keep their date type.
+func encodeValue(value interface{}) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	plainDates(&node)
+	return &node, nil
+}
+
+// plainDates unquotes the date strings of a node tree
+func plainDates(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		plain := yaml.Node{Kind: yaml.ScalarNode, Value: node.Value}
+		if plain.ShortTag() == "!!timestamp" {
+			node.Tag, node.Style = "", 0
+		}
+	}
+	for _, child := range node.Content {
+		plainDates(child)
+	}
+}
+
+// parseMapping decodes raw frontmatter into a YAML mapping node
+func parseMapping(raw string) (*yaml.Node, error) {
+	if strings.TrimSpace(raw) == "" {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("frontmatter is not a key/value mapping")
+	}
+
+	return mapping, nil
+}
+
+// ErrFieldNotFound is returned when an edit targets a field that does not exist
+var ErrFieldNotFound = errors.New("frontmatter field not found")
+
+// Field is a single top-level frontmatter entry in document order
+type Field struct {
+	Key     string
+	Value   interface{}
+	Line    int    // 1-based line of the key within the YAML block
+	Comment string // comment attached to the key or its value, if any
+}
+
+// Fields decodes raw frontmatter YAML into its top-level fields, keeping the
+// order in which they appear
+func Fields(raw string) ([]Field, error) {
+	mapping, err := parseMapping(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []Field
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, valueNode := mapping.Content[i], mapping.Content[i+1]
+
+		value, err := decodeNode(valueNode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode frontmatter field %s: %w", key.Value, err)
+		}
+
+		var comments []string
+		for _, comment := range []string{key.HeadComment, key.LineComment, valueNode.LineComment} {
+			if comment != "" {
+				comments = append(comments, comment)
+			}
+		}
+
+		fields = append(fields, Field{
+			Key:     key.Value,
+			Value:   value,
+			Line:    key.Line,
+			Comment: strings.Join(comments, " "),
+		})
+	}
+
+	return fields, nil
+}
+
+// FormatValue renders a decoded frontmatter value for display: scalars as
+// plain text, lists and maps as JSON
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}, map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// DecodeValue interprets text as a JSON value when that is unambiguous, so
+// that "3", "true", "null", "[\"a\"]" and "{\"k\": 1}" become typed values.
+// Numbers keep their type only when their canonical form is the text itself,
+// so "1.10", "007" and "1e3" stay strings, as does anything that is not JSON.
+// A quoted JSON string is unquoted, which lets "\"42\"" set the string 42.
+func DecodeValue(text string) interface{} {
+	trimmed := strings.TrimSpace(text)
+	var value interface{}
+	if err := json.Unmarshal([]byte(trimmed), &value); err != nil {
+		return trimmed
+	}
+	if number, ok := value.(float64); ok {
+		canonical, err := json.Marshal(number)
+		if err != nil || string(canonical) != trimmed {
+			return trimmed
+		}
+	}
+	return value
+}
+
+// DeleteField removes a top-level field and returns the updated note
+func DeleteField(content, field string) (string, error) {
+	doc, err := parseDocument(content)
+	if err != nil {
+		return "", err
+	}
+
+	index := fieldIndex(doc.mapping, field)
+	if !doc.found || index == -1 {
+		return "", fmt.Errorf("%w: %s", ErrFieldNotFound, field)
+	}
+
+	if err := doc.delete(index); err != nil {
+		return "", err
+	}
+	return doc.String(), nil
+}
+
+// AddToList adds values to a list field, skipping values already present. A
+// missing field is created as a list and a scalar field is turned into one.
+func AddToList(content, field string, values []interface{}) (string, error) {
+	doc, err := parseDocument(content)
+	if err != nil {
+		return "", err
+	}
+
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	if index := fieldIndex(doc.mapping, field); index != -1 {
+		current := doc.mapping.Content[index+1]
+		switch {
+		case current.Kind == yaml.SequenceNode:
+			list = current
+		case current.Kind == yaml.ScalarNode && current.Tag == "!!null":
+			list.LineComment = current.LineComment
+		case current.Kind == yaml.ScalarNode:
+			list.Content = []*yaml.Node{current}
+			list.LineComment, current.LineComment = current.LineComment, ""
+		default:
+			return "", fmt.Errorf("frontmatter field %s is not a list", field)
+		}
+	}
+
+	for _, value := range values {
+		if listContains(list, value) {
+			continue
+		}
+		item, err := encodeValue(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode value for %s: %w", field, err)
+		}
+		list.Content = append(list.Content, item)
+	}
+
+	if err := doc.set(field, list); err != nil {
+		return "", err
+	}
+	return doc.String(), nil
+}
+
+// RemoveFromList removes values from a list field. A scalar field equal to
+// one of the values is removed entirely.
+func RemoveFromList(content, field string, values []interface{}) (string, error) {
+	doc, err := parseDocument(content)
+	if err != nil {
+		return "", err
+	}
+
+	index := fieldIndex(doc.mapping, field)
+	if !doc.found || index == -1 {
+		return "", fmt.Errorf("%w: %s", ErrFieldNotFound, field)
+	}
+
+	current := doc.mapping.Content[index+1]
+	switch current.Kind {
+	case yaml.SequenceNode:
+		kept := current.Content[:0]
+		for _, item := range current.Content {
+			if !nodeMatchesAny(item, values) {
+				kept = append(kept, item)
+			}
+		}
+		current.Content = kept
+		err = doc.set(field, current)
+	case yaml.ScalarNode:
+		if nodeMatchesAny(current, values) {
+			err = doc.delete(index)
+		}
+	default:
+		return "", fmt.Errorf("frontmatter field %s is not a list", field)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return doc.String(), nil
+}
+
+// fieldIndex returns the index of a key node in a mapping, or -1
+func fieldIndex(mapping *yaml.Node, field string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// listContains reports whether a sequence node already holds value
+func listContains(list *yaml.Node, value interface{}) bool {
+	for _, item := range list.Content {
+		if nodeMatchesAny(item, []interface{}{value}) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeMatchesAny reports whether a node decodes to one of values. Values are
+// compared by their JSON form so that 1 and 1.0 or "a" and a match.
+func nodeMatchesAny(node *yaml.Node, values []interface{}) bool {
+	decoded, err := decodeNode(node)
+	if err != nil {
+		return false
+	}
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		return false
+	}
+
+	for _, value := range values {
+		other, err := json.Marshal(value)
+		if err == nil && bytes.Equal(encoded, other) {
+			return true
+		}
+	}
+	return false
+}
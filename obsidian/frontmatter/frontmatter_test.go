@@ -0,0 +1,212 @@
+package frontmatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditsKeepLineEndings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		edit    func(content string) (string, error)
+		want    string
+	}{
+		{
+			name:    "set field in CRLF note",
+			content: "---\r\ntitle: A\r\n---\r\nbody\r\nmore\r\n",
+			edit:    func(c string) (string, error) { return SetField(c, "status", "done") },
+			want:    "---\r\ntitle: A\r\nstatus: done\r\n---\r\nbody\r\nmore\r\n",
+		},
+		{
+			name:    "create frontmatter in CRLF note",
+			content: "body\r\n",
+			edit:    func(c string) (string, error) { return SetField(c, "status", "done") },
+			want:    "---\r\nstatus: done\r\n---\r\nbody\r\n",
+		},
+		{
+			name:    "delete field keeps mixed body",
+			content: "---\r\ntitle: A\r\nstatus: done\r\n---\r\nbody\nlf line\r\n",
+			edit:    func(c string) (string, error) { return DeleteField(c, "status") },
+			want:    "---\r\ntitle: A\r\n---\r\nbody\nlf line\r\n",
+		},
+		{
+			name:    "add to list in LF note",
+			content: "---\ntags: [a]\n---\nbody\n",
+			edit:    func(c string) (string, error) { return AddToList(c, "tags", []interface{}{"b"}) },
+			want:    "---\ntags: [a, b]\n---\nbody\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.edit(tt.content)
+			if err != nil {
+				t.Fatalf("edit returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("edit = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	tests := []struct {
+		text string
+		want interface{}
+	}{
+		{"3", float64(3)},
+		{"-2.5", -2.5},
+		{"1.10", "1.10"},
+		{"007", "007"},
+		{"1e3", "1e3"},
+		{"true", true},
+		{"null", nil},
+		{`"42"`, "42"},
+		{" plain text ", "plain text"},
+		{"2026-01-01", "2026-01-01"},
+	}
+
+	for _, tt := range tests {
+		if got := DecodeValue(tt.text); got != tt.want {
+			t.Errorf("DecodeValue(%q) = %#v, want %#v", tt.text, got, tt.want)
+		}
+	}
+
+	list, ok := DecodeValue(`["a", 1]`).([]interface{})
+	if !ok || len(list) != 2 || list[0] != "a" || list[1] != float64(1) {
+		t.Errorf(`DecodeValue(["a", 1]) = %#v, want a list of "a" and 1`, list)
+	}
+}
+
+func TestSetFields(t *testing.T) {
+	content := "---\ntitle: A\ndue: 2025-12-31 # moved\n---\nbody\n"
+	got, err := SetFields(content, []Field{
+		{Key: "status", Value: "open"},
+		{Key: "due", Value: DecodeValue("2026-01-01")},
+		{Key: "dates", Value: []interface{}{"2026-02-01"}},
+		{Key: "version", Value: DecodeValue("1.10")},
+	})
+	if err != nil {
+		t.Fatalf("SetFields returned error: %v", err)
+	}
+
+	want := "---\ntitle: A\ndue: 2026-01-01 # moved\nstatus: open\ndates:\n  - 2026-02-01\nversion: \"1.10\"\n---\nbody\n"
+	if got != want {
+		t.Errorf("SetFields = %q, want %q", got, want)
+	}
+
+}
+
+func TestDatesStayText(t *testing.T) {
+	content := "---\ndue: 2026-03-01\nquoted: \"2026-03-02\"\ndates:\n  - 2026-01-01\n  - 2026-01-02\n---\nbody\n"
+
+	data, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if data["due"] != "2026-03-01" || data["quoted"] != "2026-03-02" {
+		t.Errorf("Parse dates = %#v and %#v, want the text as written", data["due"], data["quoted"])
+	}
+	if dates, ok := data["dates"].([]interface{}); !ok || len(dates) != 2 || dates[0] != "2026-01-01" {
+		t.Errorf("Parse list of dates = %#v, want the text as written", data["dates"])
+	}
+
+	raw, _, _ := Split(content)
+	fields, err := Fields(raw)
+	if err != nil {
+		t.Fatalf("Fields returned error: %v", err)
+	}
+	if got := FormatValue(fields[0].Value); got != "2026-03-01" {
+		t.Errorf("FormatValue(due) = %q, want 2026-03-01", got)
+	}
+
+	added, err := AddToList(content, "dates", []interface{}{"2026-01-01", "2026-01-03"})
+	if err != nil {
+		t.Fatalf("AddToList returned error: %v", err)
+	}
+	if want := "dates:\n  - 2026-01-01\n  - 2026-01-02\n  - 2026-01-03\n---"; !strings.Contains(added, want) {
+		t.Errorf("AddToList = %q, want the existing date skipped and %q", added, want)
+	}
+
+	removed, err := RemoveFromList(content, "dates", []interface{}{"2026-01-01"})
+	if err != nil {
+		t.Fatalf("RemoveFromList returned error: %v", err)
+	}
+	if want := "dates:\n  - 2026-01-02\n---"; !strings.Contains(removed, want) {
+		t.Errorf("RemoveFromList = %q, want %q", removed, want)
+	}
+}
+
+func TestEditsKeepLayout(t *testing.T) {
+	block := "---\n# about this note\ntitle: A   # spaced comment\n\naliases:\n    - one\n    - 'two'\n\nnested:\n    key: {a: 1}\n# closing comment\n---\nbody\n"
+
+	tests := []struct {
+		name    string
+		content string
+		edit    func(content string) (string, error)
+		want    string
+	}{
+		{
+			name:    "untouched block round-trips byte for byte",
+			content: block,
+			edit:    func(c string) (string, error) { return SetFields(c, nil) },
+			want:    block,
+		},
+		{
+			name:    "changed field keeps the layout around it",
+			content: block,
+			edit:    func(c string) (string, error) { return SetField(c, "title", "B") },
+			want:    "---\n# about this note\ntitle: B # spaced comment\n\naliases:\n    - one\n    - 'two'\n\nnested:\n    key: {a: 1}\n# closing comment\n---\nbody\n",
+		},
+		{
+			name:    "new key goes before closing comments",
+			content: block,
+			edit:    func(c string) (string, error) { return SetField(c, "status", "done") },
+			want:    "---\n# about this note\ntitle: A   # spaced comment\n\naliases:\n    - one\n    - 'two'\n\nnested:\n    key: {a: 1}\nstatus: done\n# closing comment\n---\nbody\n",
+		},
+		{
+			name:    "list keeps its indentation",
+			content: block,
+			edit:    func(c string) (string, error) { return AddToList(c, "aliases", []interface{}{"three"}) },
+			want:    "---\n# about this note\ntitle: A   # spaced comment\n\naliases:\n    - one\n    - 'two'\n    - three\n\nnested:\n    key: {a: 1}\n# closing comment\n---\nbody\n",
+		},
+		{
+			name:    "list flush with its key stays flush",
+			content: "---\ntags:\n- a\ntitle: A\n---\n",
+			edit:    func(c string) (string, error) { return AddToList(c, "tags", []interface{}{"b"}) },
+			want:    "---\ntags:\n- a\n- b\ntitle: A\n---\n",
+		},
+		{
+			name:    "delete keeps the blank lines between fields",
+			content: block,
+			edit:    func(c string) (string, error) { return DeleteField(c, "aliases") },
+			want:    "---\n# about this note\ntitle: A   # spaced comment\n\n\nnested:\n    key: {a: 1}\n# closing comment\n---\nbody\n",
+		},
+		{
+			name:    "delete removes the field's own comment",
+			content: "---\na: 1\n# about b\nb: 2\nc: 3\n---\n",
+			edit:    func(c string) (string, error) { return DeleteField(c, "b") },
+			want:    "---\na: 1\nc: 3\n---\n",
+		},
+		{
+			name:    "flow mapping is written out in block style",
+			content: "---\n{a: 1, b: 2}\n---\n",
+			edit:    func(c string) (string, error) { return SetField(c, "b", 3) },
+			want:    "---\na: 1\nb: 3\n---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.edit(tt.content)
+			if err != nil {
+				t.Fatalf("edit returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("edit = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mcp-obsidian/obsidian/client"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// getActiveFileBackend returns the injected backend if it can act on the note open in Obsidian
+func getActiveFileBackend() (client.ActiveFileBackend, error) {
+	backend, err := getBackend()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vault backend: %w", err)
+	}
+
+	active, ok := backend.(client.ActiveFileBackend)
+	if !ok {
+		return nil, fmt.Errorf("active file operations are not supported by the configured vault backend")
+	}
+
+	return active, nil
+}
+
+// GetActiveFile gets the note currently open in Obsidian
+func GetActiveFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := req.GetString("format", "markdown")
+	if format != "markdown" && format != "json" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid format: %s. Must be one of: markdown, json", format)), nil
+	}
+
+	active, err := getActiveFileBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if format == "json" {
+		note, err := active.GetActiveNote()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get active file: %v", err)), nil
+		}
+
+		jsonData, err := json.MarshalIndent(note, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+
+	content, err := active.GetActiveFile()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get active file: %v", err)), nil
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Active File:\n\n")
+	fmt.Fprintf(&buf, "%s", content)
+
+	return mcp.NewToolResultText(buf.String()), nil
+}
+
+// PutActiveFile replaces the content of the note currently open in Obsidian
+func PutActiveFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	content, err := req.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError("content parameter required"), nil
+	}
+
+	active, err := getActiveFileBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := active.PutActiveFile(content); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to put content to active file: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText("Successfully replaced the content of the active file"), nil
+}
+
+// AppendActiveFile appends content to the note currently open in Obsidian
+func AppendActiveFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	content, err := req.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError("content parameter required"), nil
+	}
+
+	active, err := getActiveFileBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := active.AppendActiveFile(content); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to append content to active file: %v", err)), nil
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Successfully appended content to the active file\n\n")
+	fmt.Fprintf(&buf, "Content appended:\n%s", content)
+
+	return mcp.NewToolResultText(buf.String()), nil
+}
+
+// PatchActiveFile patches the note currently open in Obsidian
+func PatchActiveFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	operation, err := req.RequireString("operation")
+	if err != nil {
+		return mcp.NewToolResultError("operation parameter required"), nil
+	}
+
+	targetType, err := req.RequireString("target_type")
+	if err != nil {
+		return mcp.NewToolResultError("target_type parameter required"), nil
+	}
+
+	target, err := req.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target parameter required"), nil
+	}
+
+	content, err := req.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError("content parameter required"), nil
+	}
+
+	target, err = normalizePatchArgs(operation, targetType, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	active, err := getActiveFileBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := active.PatchActiveFile(operation, targetType, target, content); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to patch active file: %v", err)), nil
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "✅ Successfully patched the active file\n\n")
+	fmt.Fprintf(&buf, "📝 Operation: %s\n", operation)
+	fmt.Fprintf(&buf, "🎯 Target Type: %s\n", targetType)
+	fmt.Fprintf(&buf, "🎯 Target: %s\n", target)
+	fmt.Fprintf(&buf, "📄 Content:\n%s", content)
+
+	return mcp.NewToolResultText(buf.String()), nil
+}
+
+// DeleteActiveFile deletes the note currently open in Obsidian
+func DeleteActiveFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !req.GetBool("confirm", false) {
+		return mcp.NewToolResultError("confirm must be set to true to delete the active file"), nil
+	}
+
+	active, err := getActiveFileBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := active.DeleteActiveFile(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete active file: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText("🗑️ Successfully deleted the active file"), nil
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"sync"
+
+	"mcp-obsidian/obsidian/client"
+)
+
+var (
+	backendMu sync.RWMutex
+	// activeBackend is the vault backend injected at server startup
+	activeBackend client.VaultBackend
+
+	// envBackend is built from the environment the first time a handler
+	// runs without an injected backend, and reused, errors included
+	envBackendOnce sync.Once
+	envBackend     client.VaultBackend
+	envBackendErr  error
+)
+
+// SetBackend injects the vault backend used by all handlers. The server builds
+// it once at startup; tests and alternate entry points can pass fakes or
+// decorated backends; see client.VaultBackend for the capabilities a
+// decorator has to forward.
+func SetBackend(backend client.VaultBackend) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	activeBackend = backend
+}
+
+// getBackend returns the injected backend, falling back to one built once
+// from environment variables when none has been set
+func getBackend() (client.VaultBackend, error) {
+	backendMu.RLock()
+	backend := activeBackend
+	backendMu.RUnlock()
+
+	if backend != nil {
+		return backend, nil
+	}
+
+	envBackendOnce.Do(func() {
+		envBackend, envBackendErr = client.NewVaultBackendFromEnv()
+	})
+	return envBackend, envBackendErr
+}
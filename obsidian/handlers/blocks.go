@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/patch"
+	"mcp-obsidian/obsidian/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// blockMarkerPattern matches a trailing ^blockid marker
+var blockMarkerPattern = regexp.MustCompile(`(?:^|\s)\^[A-Za-z0-9-]+\s*$`)
+
+// GetBlockReference returns the paragraph, list item or table carrying a block ID
+func GetBlockReference(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	blockID, err := req.RequireString("block_id")
+	if err != nil {
+		return mcp.NewToolResultError("block_id parameter required"), nil
+	}
+	blockID = strings.TrimPrefix(strings.TrimSpace(blockID), "^")
+	if blockID == "" {
+		return mcp.NewToolResultError("block_id must not be empty"), nil
+	}
+
+	filePath := strings.TrimSpace(req.GetString("filepath", ""))
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	var result interface{}
+	if filePath != "" {
+		content, err := backend.GetFileContents(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
+		}
+		result = findBlockReference(filePath, content, blockID)
+	} else {
+		matches, err := findBlockReferenceInVault(backend, blockID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to search vault for block %s: %v", blockID, err)), nil
+		}
+		if matches == nil {
+			matches = []types.BlockReferenceResponse{}
+		}
+		result = map[string]interface{}{
+			"id":      blockID,
+			"exists":  len(matches) > 0,
+			"matches": matches,
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// findBlockReference locates a block ID inside a single note
+func findBlockReference(filePath, content, blockID string) types.BlockReferenceResponse {
+	response := types.BlockReferenceResponse{
+		Path: filePath,
+		ID:   blockID,
+	}
+
+	lines := strings.Split(content, "\n")
+	block, ok := patch.FindBlock(lines, blockID)
+	if !ok {
+		return response
+	}
+
+	blockLines := append([]string{}, lines[block.Start:block.ContentEnd+1]...)
+	if block.Inline {
+		marked := block.MarkerLine - block.Start
+		blockLines[marked] = strings.TrimRight(blockMarkerPattern.ReplaceAllString(blockLines[marked], ""), " \t")
+	}
+
+	response.Exists = true
+	response.Content = strings.Join(blockLines, "\n")
+	response.BlockType = block.Type
+	response.StartLine = block.Start + 1
+	response.EndLine = block.End + 1
+
+	for _, heading := range patch.Headings(lines) {
+		if heading.Line >= block.Start {
+			break
+		}
+		response.HeadingPath = heading.Path
+	}
+
+	return response
+}
+
+// findBlockReferenceInVault searches every note for a block ID. The simple
+// search narrows the candidates so only notes mentioning the ID are parsed.
+func findBlockReferenceInVault(backend client.VaultBackend, blockID string) ([]types.BlockReferenceResponse, error) {
+	var candidates []string
+	if results, err := backend.Search("^"+blockID, 0); err == nil {
+		for _, result := range results {
+			candidates = append(candidates, result.Filename)
+		}
+	} else {
+		notes, err := listVaultNotes(backend, "")
+		if err != nil {
+			return nil, err
+		}
+		candidates = notes
+	}
+
+	var matches []types.BlockReferenceResponse
+	for _, candidate := range candidates {
+		content, err := backend.GetFileContents(candidate)
+		if err != nil {
+			continue
+		}
+		if response := findBlockReference(candidate, content, blockID); response.Exists {
+			matches = append(matches, response)
+		}
+	}
+
+	return matches, nil
+}
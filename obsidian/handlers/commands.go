@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"mcp-obsidian/obsidian/client"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// commandMatch is a command palette entry returned by obsidian_list_commands
+type commandMatch struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Allowed bool   `json:"allowed"`
+	score   int
+}
+
+// getCommandBackend returns the injected backend if it can run Obsidian commands
+func getCommandBackend() (client.CommandBackend, error) {
+	backend, err := getBackend()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vault backend: %w", err)
+	}
+
+	commands, ok := backend.(client.CommandBackend)
+	if !ok {
+		return nil, fmt.Errorf("commands are not supported by the configured vault backend")
+	}
+
+	return commands, nil
+}
+
+// ListCommands lists Obsidian commands, optionally fuzzy-filtered by name or ID
+func ListCommands(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := strings.TrimSpace(req.GetString("query", ""))
+	limit := req.GetInt("limit", 0)
+	allowedOnly := req.GetBool("allowed_only", false)
+
+	commands, err := getCommandBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	available, err := commands.ListCommands()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list commands: %v", err)), nil
+	}
+
+	matches := []commandMatch{}
+	for _, command := range available {
+		score, ok := 0, true
+		if query != "" {
+			nameScore, nameOK := fuzzyScore(query, command.Name)
+			idScore, idOK := fuzzyScore(query, command.ID)
+			score, ok = max(nameScore, idScore), nameOK || idOK
+		}
+		if !ok {
+			continue
+		}
+
+		allowed := commands.CommandAllowed(command.ID)
+		if allowedOnly && !allowed {
+			continue
+		}
+
+		matches = append(matches, commandMatch{
+			ID:      command.ID,
+			Name:    command.Name,
+			Allowed: allowed,
+			score:   score,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return strings.ToLower(matches[i].Name) < strings.ToLower(matches[j].Name)
+	})
+
+	totalMatches := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	structureData := map[string]interface{}{
+		"total_commands": len(available),
+		"total_matches":  totalMatches,
+		"commands":       matches,
+	}
+	if query != "" {
+		structureData["query"] = query
+	}
+
+	jsonData, err := json.MarshalIndent(structureData, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// ExecuteCommand runs an Obsidian command by ID, subject to the configured allowlist/denylist
+func ExecuteCommand(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	commandID, err := req.RequireString("command_id")
+	if err != nil {
+		return mcp.NewToolResultError("command_id parameter required"), nil
+	}
+
+	commands, err := getCommandBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if !commands.CommandAllowed(commandID) {
+		return mcp.NewToolResultError(fmt.Sprintf("command %s is not allowed by the server's command policy", commandID)), nil
+	}
+
+	if err := commands.ExecuteCommand(commandID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to execute command: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("✅ Successfully executed command: %s", commandID)), nil
+}
+
+// fuzzyScore matches query against text case-insensitively. Substring matches
+// score highest; otherwise every query rune must appear in order, with bonuses
+// for consecutive runes and runes at word starts.
+func fuzzyScore(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	if idx := strings.Index(string(t), string(q)); idx != -1 {
+		return 1000 - len([]rune(string(t)[:idx])), true
+	}
+
+	score, qi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 5
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3
+		}
+		prev = ti
+		qi++
+	}
+
+	if qi < len(q) {
+		return 0, false
+	}
+
+	return score, true
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/links"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CopyFile duplicates a note, attachment or folder tree to a new path
+func CopyFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	source, err := req.RequireString("filepath")
+	if err != nil {
+		return mcp.NewToolResultError("filepath parameter required"), nil
+	}
+	destination, err := req.RequireString("destination")
+	if err != nil {
+		return mcp.NewToolResultError("destination parameter required"), nil
+	}
+	rewriteLinks := req.GetBool("rewrite_links", true)
+	regenerateBlockIDs := req.GetBool("regenerate_block_ids", false)
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	files, err := walkVaultFiles(backend, "")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list vault files: %v", err)), nil
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+
+	graph := links.Build(paths, nil)
+	source, destination = transferTarget(graph, source, destination)
+	copies := graph.MovedFiles(source, destination)
+	if len(copies) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("file or folder not found in vault: %s", source)), nil
+	}
+	if source == destination {
+		return mcp.NewToolResultError("source and destination are the same"), nil
+	}
+	if strings.HasPrefix(destination, source+"/") {
+		return mcp.NewToolResultError(fmt.Sprintf("cannot copy %s into itself", source)), nil
+	}
+
+	existing := make(map[string]bool)
+	for _, file := range paths {
+		existing[file] = true
+	}
+	var sources []string
+	for from := range copies {
+		sources = append(sources, from)
+	}
+	sort.Strings(sources)
+	for _, from := range sources {
+		if existing[copies[from]] {
+			return mcp.NewToolResultError(fmt.Sprintf("destination already exists: %s", copies[from])), nil
+		}
+	}
+
+	// Attachments need a byte-for-byte copy; refuse before anything is written
+	copier, canCopyBytes := backend.(client.CopyBackend)
+	for _, from := range sources {
+		if !client.IsTextFile(from) && !canCopyBytes {
+			return mcp.NewToolResultError(fmt.Sprintf("cannot copy %s: the REST API backend can only copy notes, use the filesystem backend to copy attachments", from)), nil
+		}
+	}
+
+	contents := make(map[string]string)
+	notes := make(map[string]string)
+	var notePaths []string
+	for _, from := range sources {
+		if !client.IsTextFile(from) {
+			continue
+		}
+		content, err := backend.GetFileContents(from)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read %s: %v", from, err)), nil
+		}
+		contents[from] = content
+		if isMarkdownFile(from) {
+			notes[from] = content
+			notePaths = append(notePaths, from)
+		}
+	}
+
+	// Only the copied notes are parsed; the rest of the vault is needed for link resolution
+	graph = links.Build(paths, notes)
+	var blockIDs map[string]map[string]string
+	if regenerateBlockIDs {
+		blockIDs = graph.NewBlockIDs(notePaths)
+	}
+	rewrites := graph.RewritesForCopies(copies, blockIDs, rewriteLinks)
+
+	copied := []map[string]interface{}{}
+	totalLinks, totalBlocks := 0, 0
+	var written []string
+	for _, from := range sources {
+		if !client.IsTextFile(from) {
+			if err := copier.CopyFile(from, copies[from]); err != nil {
+				return copyFailed(backend, written, copies[from], err), nil
+			}
+			written = append(written, copies[from])
+			copied = append(copied, map[string]interface{}{"from": from, "to": copies[from]})
+			continue
+		}
+
+		content, blocksRenamed := links.ReplaceBlockIDs(contents[from], blockIDs[from])
+		content, linksRewritten := links.ApplyRewrites(content, rewrites[from])
+
+		if err := backend.PutContent(copies[from], content); err != nil {
+			return copyFailed(backend, written, copies[from], err), nil
+		}
+		written = append(written, copies[from])
+
+		entry := map[string]interface{}{
+			"from": from,
+			"to":   copies[from],
+		}
+		if linksRewritten > 0 {
+			entry["links_rewritten"] = linksRewritten
+		}
+		if blocksRenamed > 0 {
+			entry["block_ids_regenerated"] = blocksRenamed
+		}
+		copied = append(copied, entry)
+		totalLinks += linksRewritten
+		totalBlocks += blocksRenamed
+	}
+
+	return jsonResult(map[string]interface{}{
+		"source":                      source,
+		"destination":                 destination,
+		"copied":                      copied,
+		"total_files":                 len(copied),
+		"total_links_rewritten":       totalLinks,
+		"total_block_ids_regenerated": totalBlocks,
+	})
+}
+
+// copyFailed removes the copies already written, so a failed copy leaves no
+// partial tree behind, and reports the failure
+func copyFailed(backend client.VaultBackend, written []string, failed string, err error) *mcp.CallToolResult {
+	var leftover []string
+	for _, file := range written {
+		if deleteErr := backend.DeleteFile(file); deleteErr != nil {
+			leftover = append(leftover, file)
+		}
+	}
+
+	message := fmt.Sprintf("failed to write %s: %v", failed, err)
+	if len(written) > 0 {
+		message += fmt.Sprintf("; removed the %d files already copied", len(written)-len(leftover))
+	}
+	if len(leftover) > 0 {
+		message += fmt.Sprintf(", could not remove: %s", strings.Join(leftover, ", "))
+	}
+	return mcp.NewToolResultError(message)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SearchDataview runs a Dataview TABLE query and returns the rows as JSON
+func SearchDataview(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := req.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError("query parameter required"), nil
+	}
+
+	query = strings.TrimSpace(query)
+	if fields := strings.Fields(query); len(fields) == 0 || !strings.EqualFold(fields[0], "TABLE") {
+		return mcp.NewToolResultError("only Dataview TABLE queries are supported, e.g. TABLE status, due FROM #project"), nil
+	}
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	dataview, ok := backend.(client.DataviewBackend)
+	if !ok {
+		return mcp.NewToolResultError("Dataview search is not supported by the configured vault backend"), nil
+	}
+
+	rows, err := dataview.SearchDQL(query)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to perform Dataview search: %v", err)), nil
+	}
+
+	structureData := map[string]interface{}{
+		"query":      query,
+		"total_rows": len(rows),
+		"columns":    dqlColumns(rows),
+		"rows":       rows,
+	}
+
+	jsonData, err := json.MarshalIndent(structureData, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// dqlColumns returns the union of row columns in first-seen order
+func dqlColumns(rows []types.DQLRow) []string {
+	columns := []string{}
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for _, column := range row.Columns {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
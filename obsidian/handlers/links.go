@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/links"
+	"mcp-obsidian/obsidian/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxNeighborDepth bounds how far obsidian_get_neighbors walks the link graph
+const maxNeighborDepth = 5
+
+// GetBacklinks lists the links from other notes to a note or attachment
+func GetBacklinks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath, err := req.RequireString("filepath")
+	if err != nil {
+		return mcp.NewToolResultError("filepath parameter required"), nil
+	}
+	includeEmbeds := req.GetBool("include_embeds", true)
+
+	graph, file, errResult := loadLinkGraph(filePath)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	backlinks := []types.NoteLink{}
+	sources := make(map[string]bool)
+	for _, link := range graph.Backlinks(file) {
+		if link.Embed && !includeEmbeds {
+			continue
+		}
+		backlinks = append(backlinks, link)
+		sources[link.Source] = true
+	}
+
+	return jsonResult(map[string]interface{}{
+		"filepath":        file,
+		"total_backlinks": len(backlinks),
+		"linking_notes":   len(sources),
+		"backlinks":       backlinks,
+	})
+}
+
+// GetOutgoingLinks lists the links in a note with the files they resolve to
+func GetOutgoingLinks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath, err := req.RequireString("filepath")
+	if err != nil {
+		return mcp.NewToolResultError("filepath parameter required"), nil
+	}
+
+	graph, file, errResult := loadLinkGraph(filePath)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	outgoing := graph.Outgoing(file)
+	if outgoing == nil {
+		outgoing = []types.NoteLink{}
+	}
+
+	unresolved := 0
+	for _, link := range outgoing {
+		if link.Resolved == "" {
+			unresolved++
+		}
+	}
+
+	return jsonResult(map[string]interface{}{
+		"filepath":    file,
+		"total_links": len(outgoing),
+		"unresolved":  unresolved,
+		"links":       outgoing,
+	})
+}
+
+// GetNeighbors lists the notes and attachments within a number of links of a note
+func GetNeighbors(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath, err := req.RequireString("filepath")
+	if err != nil {
+		return mcp.NewToolResultError("filepath parameter required"), nil
+	}
+
+	depth := req.GetInt("depth", 1)
+	if depth < 1 || depth > maxNeighborDepth {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid depth: %d. Must be between 1 and %d", depth, maxNeighborDepth)), nil
+	}
+
+	direction := req.GetString("direction", links.DirectionBoth)
+	switch direction {
+	case links.DirectionBoth, links.DirectionOutgoing, links.DirectionIncoming:
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid direction: %s. Must be one of: both, outgoing, incoming", direction)), nil
+	}
+
+	graph, file, errResult := loadLinkGraph(filePath)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	neighbors := graph.Neighbors(file, depth, direction)
+	if neighbors == nil {
+		neighbors = []types.NoteNeighbor{}
+	}
+
+	return jsonResult(map[string]interface{}{
+		"filepath":        file,
+		"depth":           depth,
+		"direction":       direction,
+		"total_neighbors": len(neighbors),
+		"neighbors":       neighbors,
+	})
+}
+
+// LintLinks reports broken links, orphan notes and unused attachments
+func LintLinks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := req.GetInt("limit", 0)
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	findings, notesChecked, err := LintVault(backend, req.GetString("checks", ""), req.GetString("folder", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	counts := links.CountFindings(findings)
+	total := len(findings)
+	if limit > 0 && len(findings) > limit {
+		findings = findings[:limit]
+	}
+	if findings == nil {
+		findings = []types.LinkFinding{}
+	}
+
+	return jsonResult(map[string]interface{}{
+		"total_findings": total,
+		"notes_checked":  notesChecked,
+		"counts":         counts,
+		"findings":       findings,
+	})
+}
+
+// lintOptions parses the comma-separated checks and folder of a lint request
+func lintOptions(checks, folder string) (links.LintOptions, error) {
+	options := links.LintOptions{Folder: folder}
+	for _, check := range strings.Split(checks, ",") {
+		check = strings.TrimSpace(check)
+		if check == "" {
+			continue
+		}
+		valid := false
+		for _, known := range links.LintChecks {
+			valid = valid || check == known
+		}
+		if !valid {
+			return options, fmt.Errorf("invalid check: %s. Must be one of: %s", check, strings.Join(links.LintChecks, ", "))
+		}
+		options.Checks = append(options.Checks, check)
+	}
+	return options, nil
+}
+
+// LintVault builds the link graph of a backend's vault and lints it. It backs
+// the lint-links CLI command.
+func LintVault(backend client.VaultBackend, checks, folder string) ([]types.LinkFinding, int, error) {
+	options, err := lintOptions(checks, folder)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	graph, err := BuildLinkGraph(backend)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build link graph: %w", err)
+	}
+
+	return graph.Lint(options), len(graph.Notes()), nil
+}
+
+// BuildLinkGraph reads every note in the vault and builds its link graph
+func BuildLinkGraph(backend client.VaultBackend) (*links.Graph, error) {
+	files, err := walkVaultFiles(backend, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var paths, notePaths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+		if isMarkdownFile(file.Path) {
+			notePaths = append(notePaths, file.Path)
+		}
+	}
+
+	notes := make(map[string]string, len(notePaths))
+	for _, note := range fetchNotes(backend, notePaths) {
+		notes[note.Path] = note.Content
+	}
+
+	return links.Build(paths, notes), nil
+}
+
+// loadLinkGraph builds the link graph and locates filePath in it. On failure
+// it returns the tool error to send back.
+func loadLinkGraph(filePath string) (*links.Graph, string, *mcp.CallToolResult) {
+	backend, err := getBackend()
+	if err != nil {
+		return nil, "", mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err))
+	}
+
+	graph, err := BuildLinkGraph(backend)
+	if err != nil {
+		return nil, "", mcp.NewToolResultError(fmt.Sprintf("failed to build link graph: %v", err))
+	}
+
+	file, ok := graph.Resolver.Lookup(filePath)
+	if !ok {
+		return nil, "", mcp.NewToolResultError(fmt.Sprintf("file not found in vault: %s", filePath))
+	}
+
+	return graph, file, nil
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/types"
+)
+
+// listWorkers bounds the number of concurrent directory listings
+const listWorkers = 8
+
+// treeOptions filters a recursive directory listing
+type treeOptions struct {
+	MaxDepth      int // 0 for unlimited
+	Include       []string
+	Exclude       []string
+	Extensions    map[string]bool
+	IncludeHidden bool
+}
+
+// treeWalker lists a directory tree, running at most listWorkers backend
+// listings at a time
+type treeWalker struct {
+	backend client.VaultBackend
+	options treeOptions
+	sem     chan struct{}
+}
+
+// listFileTree recursively lists dirPath ("" for the vault root) as a nested
+// tree. Notes the backend lists without size or modification time get them
+// from their note metadata.
+func listFileTree(backend client.VaultBackend, dirPath string, options treeOptions) ([]*types.FileTreeNode, error) {
+	walker := &treeWalker{
+		backend: backend,
+		options: options,
+		sem:     make(chan struct{}, listWorkers),
+	}
+
+	nodes, err := walker.list(dirPath, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	byPath := make(map[string]*types.FileTreeNode)
+	walkFileTree(nodes, func(node *types.FileTreeNode) {
+		if node.Type == "file" && node.Modified == "" && isMarkdownFile(node.Path) {
+			missing = append(missing, node.Path)
+			byPath[node.Path] = node
+		}
+	})
+	for _, note := range fetchNotes(backend, missing) {
+		if node := byPath[note.Path]; node != nil {
+			node.Size = note.Stat.Size
+			node.Modified = time.UnixMilli(note.Stat.Mtime).Format("2006-01-02 15:04:05")
+		}
+	}
+
+	return nodes, nil
+}
+
+// list lists one directory and, below max_depth, its subdirectories in parallel
+func (w *treeWalker) list(dirPath string, depth int) ([]*types.FileTreeNode, error) {
+	w.sem <- struct{}{}
+	var files []types.FileInfo
+	var err error
+	if dirPath == "" {
+		files, err = w.backend.ListFilesInVault()
+	} else {
+		files, err = w.backend.ListFilesInDir(dirPath)
+	}
+	<-w.sem
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*types.FileTreeNode
+	for _, file := range files {
+		fullPath := joinVaultPath(dirPath, strings.TrimSuffix(file.Path, "/"))
+		name := path.Base(fullPath)
+		if !w.options.IncludeHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+		if matchesAnyGlob(w.options.Exclude, fullPath) {
+			continue
+		}
+
+		node := &types.FileTreeNode{
+			Name: name,
+			Path: fullPath,
+			Type: file.Type,
+		}
+		if file.Type == "directory" {
+			node.Truncated = w.options.MaxDepth > 0 && depth >= w.options.MaxDepth
+		} else {
+			if len(w.options.Include) > 0 && !matchesAnyGlob(w.options.Include, fullPath) {
+				continue
+			}
+			if !matchesExtension(fullPath, w.options.Extensions) {
+				continue
+			}
+			node.Size = file.Size
+			if !file.ModifiedTime.IsZero() {
+				node.Modified = file.ModifiedTime.Format("2006-01-02 15:04:05")
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(nodes))
+	for i, node := range nodes {
+		if node.Type != "directory" || node.Truncated {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, node *types.FileTreeNode) {
+			defer wg.Done()
+			node.Children, errs[i] = w.list(node.Path, depth+1)
+		}(i, node)
+	}
+	wg.Wait()
+
+	filtered := len(w.options.Include) > 0 || len(w.options.Extensions) > 0
+	kept := nodes[:0]
+	for i, node := range nodes {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		// With file filters, folders that ended up empty are noise
+		if filtered && node.Type == "directory" && !node.Truncated && len(node.Children) == 0 {
+			continue
+		}
+		kept = append(kept, node)
+	}
+
+	return kept, nil
+}
+
+// walkFileTree calls fn for every node of a tree, parents before children
+func walkFileTree(nodes []*types.FileTreeNode, fn func(node *types.FileTreeNode)) {
+	for _, node := range nodes {
+		fn(node)
+		walkFileTree(node.Children, fn)
+	}
+}
+
+// parseGlobs parses a comma-separated list of glob patterns
+func parseGlobs(value string) []string {
+	var globs []string
+	for _, glob := range strings.Split(value, ",") {
+		if glob = strings.Trim(strings.TrimSpace(glob), "/"); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+	return globs
+}
+
+// matchesAnyGlob reports whether a vault path matches one of the patterns.
+// Patterns without a "/" match the file or folder name; others match the
+// whole vault path, with "**" standing for any number of folders.
+func matchesAnyGlob(globs []string, filePath string) bool {
+	for _, glob := range globs {
+		if !strings.Contains(glob, "/") {
+			if matched, err := path.Match(glob, path.Base(filePath)); err == nil && matched {
+				return true
+			}
+			continue
+		}
+		if matchSegments(strings.Split(glob, "/"), strings.Split(filePath, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], parts[0]); err != nil || !matched {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
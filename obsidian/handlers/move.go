@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/links"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MoveFile moves or renames a note or folder and rewrites every link to it
+func MoveFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	source, err := req.RequireString("filepath")
+	if err != nil {
+		return mcp.NewToolResultError("filepath parameter required"), nil
+	}
+	destination, err := req.RequireString("destination")
+	if err != nil {
+		return mcp.NewToolResultError("destination parameter required"), nil
+	}
+	dryRun := req.GetBool("dry_run", false)
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	mover, ok := backend.(client.MoveBackend)
+	if !ok && !dryRun {
+		return mcp.NewToolResultError("moving files is not supported by the configured vault backend"), nil
+	}
+
+	graph, err := BuildLinkGraph(backend)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to build link graph: %v", err)), nil
+	}
+
+	source, destination = transferTarget(graph, source, destination)
+	moves := graph.MovedFiles(source, destination)
+	if len(moves) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("file or folder not found in vault: %s", source)), nil
+	}
+	if source == destination {
+		return mcp.NewToolResultError("source and destination are the same"), nil
+	}
+	if strings.HasPrefix(destination, source+"/") {
+		return mcp.NewToolResultError(fmt.Sprintf("cannot move %s into itself", source)), nil
+	}
+
+	existing := make(map[string]bool)
+	for _, file := range graph.Files() {
+		existing[file] = true
+	}
+	var sources []string
+	for from := range moves {
+		sources = append(sources, from)
+	}
+	sort.Strings(sources)
+
+	var moved []map[string]string
+	for _, from := range sources {
+		to := moves[from]
+		if existing[to] && moves[to] == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("destination already exists: %s", to)), nil
+		}
+		moved = append(moved, map[string]string{"from": from, "to": to})
+	}
+
+	rewrites := graph.RewritesForMoves(moves)
+	var notes []string
+	for note := range rewrites {
+		notes = append(notes, note)
+	}
+	sort.Strings(notes)
+
+	// Read every affected note before anything moves so a failed read leaves the vault untouched
+	updated := make(map[string]string)
+	counts := make(map[string]int)
+	for _, note := range notes {
+		content, err := backend.GetFileContents(note)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read %s: %v", note, err)), nil
+		}
+		updated[note], counts[note] = links.ApplyRewrites(content, rewrites[note])
+	}
+
+	if !dryRun {
+		if err := mover.MoveFile(source, destination); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to move %s: %v", source, err)), nil
+		}
+
+		for _, note := range notes {
+			target := note
+			if to, ok := moves[note]; ok {
+				target = to
+			}
+			if err := backend.PutContent(target, updated[note]); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("moved %s but failed to update links in %s: %v", source, target, err)), nil
+			}
+		}
+	}
+
+	updatedFiles := []map[string]interface{}{}
+	total := 0
+	for _, note := range notes {
+		target := note
+		if to, ok := moves[note]; ok {
+			target = to
+		}
+		updatedFiles = append(updatedFiles, map[string]interface{}{
+			"path":            target,
+			"links_rewritten": counts[note],
+			"changes":         rewrites[note],
+		})
+		total += counts[note]
+	}
+
+	return jsonResult(map[string]interface{}{
+		"source":                source,
+		"destination":           destination,
+		"dry_run":               dryRun,
+		"moved":                 moved,
+		"updated_files":         updatedFiles,
+		"total_links_rewritten": total,
+	})
+}
+
+// transferTarget normalises the source and destination of a move or copy. A
+// destination ending in "/" puts the source into that folder, and a note
+// renamed without an extension keeps the extension it had.
+func transferTarget(graph *links.Graph, source, destination string) (string, string) {
+	source = strings.Trim(source, "/")
+	if strings.HasSuffix(destination, "/") || destination == "" {
+		destination = path.Join(destination, path.Base(source))
+	}
+	destination = strings.Trim(path.Clean("/"+destination), "/")
+
+	for _, file := range graph.Files() {
+		if strings.HasPrefix(file, source+"/") {
+			return source, destination
+		}
+	}
+
+	// A single note may be named the way links refer to it
+	if file, ok := graph.Resolver.Lookup(source); ok {
+		source = file
+		if path.Ext(destination) == "" {
+			destination += path.Ext(file)
+		}
+	}
+
+	return source, destination
+}
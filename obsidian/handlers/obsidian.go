@@ -4,13 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/frontmatter"
+	"mcp-obsidian/obsidian/markdown"
+	"mcp-obsidian/obsidian/patch"
 	"mcp-obsidian/obsidian/types"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -19,12 +24,12 @@ import (
 
 // ListFilesInVault lists all files in the vault
 func ListFilesInVault(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	files, err := obsidianClient.ListFilesInVault()
+	files, err := backend.ListFilesInVault()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list files in vault: %v", err)), nil
 	}
@@ -61,63 +66,51 @@ func ListFilesInDir(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		return mcp.NewToolResultError("dirpath parameter required"), nil
 	}
 
-	// Parse max_depth parameter (default: 3 for recursive listing)
-	maxDepth := 3
-	if args, ok := req.Params.Arguments.(map[string]interface{}); ok {
-		if depthStr, ok := args["max_depth"].(string); ok {
-			if depth, err := strconv.Atoi(depthStr); err == nil {
-				maxDepth = depth
-			}
-		}
+	// Parse max_depth parameter (default: 3, 0 for unlimited)
+	maxDepth := req.GetInt("max_depth", 3)
+	if maxDepth < 0 {
+		maxDepth = 0
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	options := treeOptions{
+		MaxDepth:      maxDepth,
+		Include:       parseGlobs(req.GetString("include", "")),
+		Exclude:       parseGlobs(req.GetString("exclude", "")),
+		Extensions:    parseExtensions(req.GetString("extensions", "")),
+		IncludeHidden: req.GetBool("include_hidden", false),
+	}
+
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	files, err := obsidianClient.ListFilesInDir(dirPath)
+	dirPath = strings.Trim(dirPath, "/")
+	items, err := listFileTree(backend, dirPath, options)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list files in directory %s: %v", dirPath, err)), nil
 	}
+	if items == nil {
+		items = []*types.FileTreeNode{}
+	}
 
-	// Convert to JSON structure
-	fileList := make([]map[string]interface{}, 0, len(files))
-
-	for _, file := range files {
-		fileInfo := map[string]interface{}{
-			"name": file.Name,
-			"type": file.Type,
-			"path": file.Path,
-		}
-
-		// Add file-specific information
-		if file.Type == "file" {
-			if file.Size > 0 {
-				fileInfo["size"] = file.Size
-			}
-			if !file.ModifiedTime.IsZero() {
-				fileInfo["modified"] = file.ModifiedTime.Format("2006-01-02 15:04:05")
-			}
-		}
-
-		// If it's a directory and max_depth > 1, recursively list contents
-		if file.Type == "directory" && maxDepth > 1 {
-			// For now, we'll just indicate it's a directory with potential children
-			// In a full implementation, we'd recursively call ListFilesInDir
-			fileInfo["has_children"] = true
-			fileInfo["children_depth"] = maxDepth - 1
+	totalFiles, totalDirectories := 0, 0
+	walkFileTree(items, func(node *types.FileTreeNode) {
+		if node.Type == "directory" {
+			totalDirectories++
+		} else {
+			totalFiles++
 		}
-
-		fileList = append(fileList, fileInfo)
-	}
+	})
 
 	// Create JSON structure
 	structureData := map[string]interface{}{
-		"directory":   dirPath,
-		"max_depth":   maxDepth,
-		"total_items": len(files),
-		"items":       fileList,
+		"directory":         dirPath,
+		"max_depth":         maxDepth,
+		"total_items":       totalFiles + totalDirectories,
+		"total_files":       totalFiles,
+		"total_directories": totalDirectories,
+		"items":             items,
 	}
 
 	// Convert to JSON
@@ -136,18 +129,28 @@ func GetFileContents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 		return mcp.NewToolResultError("filepath parameter required"), nil
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	content, err := obsidianClient.GetFileContents(filePath)
+	content, err := backend.GetFileContents(filePath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
 	}
 
 	var buf strings.Builder
 	fmt.Fprintf(&buf, "File: %s\n\n", filePath)
+
+	if target := req.GetString("lines", ""); target != "" {
+		numbered, err := hashedLines(content, target)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read lines of %s: %v", filePath, err)), nil
+		}
+		buf.WriteString(numbered)
+		return mcp.NewToolResultText(buf.String()), nil
+	}
+
 	fmt.Fprintf(&buf, "%s", content)
 
 	return mcp.NewToolResultText(buf.String()), nil
@@ -169,12 +172,12 @@ func Search(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult,
 		}
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	results, err := obsidianClient.Search(query, contextLength)
+	results, err := backend.Search(query, contextLength)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to search for '%s': %v", query, err)), nil
 	}
@@ -211,12 +214,12 @@ func AppendContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		return mcp.NewToolResultError("content parameter required"), nil
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	err = obsidianClient.AppendContent(filePath, content)
+	err = backend.AppendContent(filePath, content)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to append content to %s: %v", filePath, err)), nil
 	}
@@ -224,6 +227,7 @@ func AppendContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	var buf strings.Builder
 	fmt.Fprintf(&buf, "Successfully appended content to %s\n\n", filePath)
 	fmt.Fprintf(&buf, "Content appended:\n%s", content)
+	buf.WriteString(openAfterWrite(req, filePath))
 
 	return mcp.NewToolResultText(buf.String()), nil
 }
@@ -240,18 +244,19 @@ func PutContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 		return mcp.NewToolResultError("content parameter required"), nil
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	err = obsidianClient.PutContent(filePath, content)
+	err = backend.PutContent(filePath, content)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to put content to %s: %v", filePath, err)), nil
 	}
 
 	var buf strings.Builder
 	fmt.Fprintf(&buf, "Successfully created/updated %s", filePath)
+	buf.WriteString(openAfterWrite(req, filePath))
 
 	return mcp.NewToolResultText(buf.String()), nil
 }
@@ -276,12 +281,12 @@ func DeleteFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 		return mcp.NewToolResultError("confirm must be set to true to delete a file"), nil
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	err = obsidianClient.DeleteFile(filePath)
+	err = backend.DeleteFile(filePath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to delete %s: %v", filePath, err)), nil
 	}
@@ -314,11 +319,65 @@ func PatchContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		return mcp.NewToolResultError("target parameter required"), nil
 	}
 
+	// delete, only valid for lines and regex targets, takes no content
 	content, err := req.RequireString("content")
-	if err != nil {
+	if err != nil && operation != "delete" {
 		return mcp.NewToolResultError("content parameter required"), nil
 	}
 
+	// Line and regex targets are patched here for every backend
+	if targetType == "lines" || targetType == "regex" {
+		return patchInServer(req, filePath, operation, targetType, target, content)
+	}
+
+	target, err = normalizePatchArgs(operation, targetType, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	// Validate that the target exists before patching
+	if targetType == "heading" {
+		fileContent, err := backend.GetFileContents(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for validation: %v", err)), nil
+		}
+
+		resolved, headingPaths := resolveHeadingTarget(parseMarkdownElements(fileContent), target)
+		if resolved == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("target heading '%s' not found or ambiguous in file, use a nested target such as Parent::Child. Available headings: %v", target, headingPaths)), nil
+		}
+
+		// Use the exact heading path from the file
+		target = resolved
+	}
+
+	err = backend.PatchContent(filePath, operation, targetType, target, content)
+	if err != nil {
+		// Provide more detailed error information for debugging
+		errorMsg := fmt.Sprintf("failed to patch content in %s: %v\n\nDebug info:\n- File: %s\n- Operation: %s\n- Target Type: %s\n- Target: '%s'\n- Content length: %d chars",
+			filePath, err, filePath, operation, targetType, target, len(content))
+		return mcp.NewToolResultError(errorMsg), nil
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "✅ Successfully patched content in %s\n\n", filePath)
+	fmt.Fprintf(&buf, "📝 Operation: %s\n", operation)
+	fmt.Fprintf(&buf, "🎯 Target Type: %s\n", targetType)
+	fmt.Fprintf(&buf, "🎯 Target: %s\n", target)
+	fmt.Fprintf(&buf, "📄 Content:\n%s", content)
+	buf.WriteString(openAfterWrite(req, filePath))
+
+	return mcp.NewToolResultText(buf.String()), nil
+}
+
+// normalizePatchArgs validates a patch operation and target type and returns
+// the target in the format expected by the Obsidian API
+func normalizePatchArgs(operation, targetType, target string) (string, error) {
 	// Validate target type
 	validTargetTypes := map[string]bool{
 		"heading":     true,
@@ -326,7 +385,7 @@ func PatchContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		"frontmatter": true,
 	}
 	if !validTargetTypes[targetType] {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid target_type: %s. Must be one of: heading, block, frontmatter", targetType)), nil
+		return "", fmt.Errorf("invalid target_type: %s. Must be one of: heading, block, frontmatter", targetType)
 	}
 
 	// Validate operation
@@ -336,7 +395,7 @@ func PatchContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		"replace": true,
 	}
 	if !validOperations[operation] {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid operation: %s. Must be one of: append, prepend, replace", operation)), nil
+		return "", fmt.Errorf("invalid operation: %s. Must be one of: append, prepend, replace", operation)
 	}
 
 	// Handle target formatting based on type
@@ -356,75 +415,7 @@ func PatchContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		target = strings.TrimSpace(target)
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
-	}
-
-	// Validate that the target exists before patching
-	if targetType == "heading" {
-		fileContent, err := obsidianClient.GetFileContents(filePath)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for validation: %v", err)), nil
-		}
-
-		elements := parseMarkdownElements(fileContent)
-		nestedElements := buildNestedStructure(elements)
-
-		// Check if the target heading exists with exact matching
-		targetExists := false
-		var exactMatch string
-		for _, element := range nestedElements {
-			if element.Element.Type == "heading" {
-				// Try exact match first
-				if element.Element.Title == target {
-					targetExists = true
-					exactMatch = element.Element.Title
-					break
-				}
-				// Fallback to case-insensitive match
-				if strings.EqualFold(element.Element.Title, target) {
-					targetExists = true
-					exactMatch = element.Element.Title
-					break
-				}
-			}
-		}
-
-		if !targetExists {
-			// Try to find similar headings
-			var similarHeadings []string
-			for _, element := range nestedElements {
-				if element.Element.Type == "heading" {
-					similarHeadings = append(similarHeadings, element.Element.Title)
-				}
-			}
-
-			return mcp.NewToolResultError(fmt.Sprintf("target heading '%s' not found in file. Available headings: %v", target, similarHeadings)), nil
-		}
-
-		// Use the exact match from the file if we found one
-		if exactMatch != "" && exactMatch != target {
-			target = exactMatch
-		}
-	}
-
-	err = obsidianClient.PatchContent(filePath, operation, targetType, target, content)
-	if err != nil {
-		// Provide more detailed error information for debugging
-		errorMsg := fmt.Sprintf("failed to patch content in %s: %v\n\nDebug info:\n- File: %s\n- Operation: %s\n- Target Type: %s\n- Target: '%s'\n- Content length: %d chars",
-			filePath, err, filePath, operation, targetType, target, len(content))
-		return mcp.NewToolResultError(errorMsg), nil
-	}
-
-	var buf strings.Builder
-	fmt.Fprintf(&buf, "✅ Successfully patched content in %s\n\n", filePath)
-	fmt.Fprintf(&buf, "📝 Operation: %s\n", operation)
-	fmt.Fprintf(&buf, "🎯 Target Type: %s\n", targetType)
-	fmt.Fprintf(&buf, "🎯 Target: %s\n", target)
-	fmt.Fprintf(&buf, "📄 Content:\n%s", content)
-
-	return mcp.NewToolResultText(buf.String()), nil
+	return target, nil
 }
 
 // SearchJSON performs a complex search using JsonLogic
@@ -439,12 +430,17 @@ func SearchJSON(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 		return mcp.NewToolResultError(fmt.Sprintf("invalid JSON query: %v", err)), nil
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	jsonSearch, ok := backend.(client.JSONSearchBackend)
+	if !ok {
+		return mcp.NewToolResultError("JsonLogic search is not supported by the configured vault backend"), nil
 	}
 
-	results, err := obsidianClient.SearchJSON(query)
+	results, err := jsonSearch.SearchJSON(query)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to perform JSON search: %v", err)), nil
 	}
@@ -471,183 +467,243 @@ func SearchJSON(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 
 // TestConnection tests the connection to Obsidian
 func TestConnection(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	err = obsidianClient.TestConnection()
+	err = backend.TestConnection()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("connection test failed: %v", err)), nil
 	}
 
 	// Get configuration from environment variables for display
-	host := "127.0.0.1"
-	if envHost := os.Getenv("OBSIDIAN_HOST"); envHost != "" {
-		host = envHost
-	}
+	config := client.LoadConfigFromEnv()
 
-	port := "27124"
-	if envPort := os.Getenv("OBSIDIAN_PORT"); envPort != "" {
-		port = envPort
-	}
-
-	protocol := "https"
-	if envProtocol := os.Getenv("OBSIDIAN_PROTOCOL"); envProtocol != "" {
-		protocol = envProtocol
+	var buf strings.Builder
+	if config.Backend == client.BackendFilesystem {
+		fmt.Fprintf(&buf, "Successfully opened vault on disk!\n\n")
+		fmt.Fprintf(&buf, "Configuration:\n")
+		fmt.Fprintf(&buf, "  Backend: %s\n", config.Backend)
+		fmt.Fprintf(&buf, "  Vault Path: %s\n", config.VaultPath)
+		return mcp.NewToolResultText(buf.String()), nil
 	}
 
-	vaultPath := os.Getenv("OBSIDIAN_VAULT_PATH")
-
-	var buf strings.Builder
 	fmt.Fprintf(&buf, "Successfully connected to Obsidian!\n\n")
 	fmt.Fprintf(&buf, "Configuration:\n")
-	fmt.Fprintf(&buf, "  Host: %s\n", host)
-	fmt.Fprintf(&buf, "  Port: %s\n", port)
-	fmt.Fprintf(&buf, "  Protocol: %s\n", protocol)
-	if vaultPath != "" {
-		fmt.Fprintf(&buf, "  Vault Path: %s\n", vaultPath)
+	fmt.Fprintf(&buf, "  Host: %s\n", config.Host)
+	fmt.Fprintf(&buf, "  Port: %s\n", config.Port)
+	fmt.Fprintf(&buf, "  Protocol: %s\n", config.Protocol)
+	if config.VaultPath != "" {
+		fmt.Fprintf(&buf, "  Vault Path: %s\n", config.VaultPath)
 	}
 
 	return mcp.NewToolResultText(buf.String()), nil
 }
 
-// GetPeriodicNote gets or creates a periodic note
-func GetPeriodicNote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	period, err := req.RequireString("period")
+// GetFrontmatter gets frontmatter from a file
+func GetFrontmatter(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filepath, err := req.RequireString("filepath")
 	if err != nil {
-		return mcp.NewToolResultError("period parameter required"), nil
+		return mcp.NewToolResultError("filepath parameter required"), nil
 	}
 
-	date, err := req.RequireString("date")
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError("date parameter required"), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	result, err := backend.GetFrontmatter(filepath)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get frontmatter: %v", err)), nil
 	}
 
-	note, err := obsidianClient.GetPeriodicNote(period, date)
+	jsonData, err := json.Marshal(result.Data)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get periodic note: %v", err)), nil
-	}
-
-	var buf strings.Builder
-	fmt.Fprintf(&buf, "Periodic Note: %s (%s)\n\n", period, date)
-	fmt.Fprintf(&buf, "Path: %s\n", note.Path)
-	fmt.Fprintf(&buf, "Exists: %t\n", note.Exists)
-	if note.Content != "" {
-		fmt.Fprintf(&buf, "\nContent:\n%s", note.Content)
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal frontmatter: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(buf.String()), nil
+	return mcp.NewToolResultText(fmt.Sprintf("Frontmatter for %s: %s", filepath, string(jsonData))), nil
 }
 
-// CreatePeriodicNote creates a new periodic note
-func CreatePeriodicNote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	period, err := req.RequireString("period")
+// SetFrontmatter edits frontmatter for a file. Operation "set" replaces a
+// field (or every field of a "data" object), "add" and "remove" edit list
+// fields and "delete" removes a field. Values are JSON when they parse as
+// JSON, so lists, numbers, booleans and objects keep their type.
+func SetFrontmatter(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filepath, err := req.RequireString("filepath")
 	if err != nil {
-		return mcp.NewToolResultError("period parameter required"), nil
+		return mcp.NewToolResultError("filepath parameter required"), nil
 	}
 
-	date, err := req.RequireString("date")
-	if err != nil {
-		return mcp.NewToolResultError("date parameter required"), nil
+	operation := req.GetString("operation", "set")
+	switch operation {
+	case "set", "add", "remove", "delete":
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid operation: %s. Must be one of: set, add, remove, delete", operation)), nil
 	}
 
-	content, err := req.RequireString("content")
-	if err != nil {
-		return mcp.NewToolResultError("content parameter required"), nil
-	}
+	args, _ := req.Params.Arguments.(map[string]interface{})
+	field := req.GetString("field", "")
+	value, hasValue := frontmatterArgument(args, "value")
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	note, err := obsidianClient.CreatePeriodicNote(period, date, content)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create periodic note: %v", err)), nil
-	}
+	if data, ok := args["data"]; ok && data != nil && field == "" {
+		if operation != "set" {
+			return mcp.NewToolResultError("data parameter is only supported with operation set"), nil
+		}
+		fields, err := frontmatterData(data)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 
-	var buf strings.Builder
-	fmt.Fprintf(&buf, "Created Periodic Note: %s (%s)\n\n", period, date)
-	fmt.Fprintf(&buf, "Path: %s\n", note.Path)
-	fmt.Fprintf(&buf, "Exists: %t\n", note.Exists)
+		content, err := backend.GetFileContents(filepath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filepath, err)), nil
+		}
+		updated, err := frontmatter.SetFields(content, fields)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to set frontmatter: %v", err)), nil
+		}
+		if err := backend.PutContent(filepath, updated); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to write %s: %v", filepath, err)), nil
+		}
 
-	return mcp.NewToolResultText(buf.String()), nil
-}
+		names := make([]string, len(fields))
+		for i, f := range fields {
+			names[i] = f.Key
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Frontmatter fields %s set for file %s", strings.Join(names, ", "), filepath)), nil
+	}
 
-// GetRecentChanges gets recent changes in the vault
-func GetRecentChanges(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return mcp.NewToolResultError("Recent changes endpoint is not available in this version of the Obsidian Local REST API. Use the search functionality to find recent files."), nil
-}
+	if field == "" {
+		return mcp.NewToolResultError("field parameter required"), nil
+	}
+	if !hasValue && operation != "delete" {
+		return mcp.NewToolResultError("value parameter required"), nil
+	}
 
-// GetTags gets all tags in the vault
-func GetTags(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return mcp.NewToolResultError("Tags endpoint is not available in this version of the Obsidian Local REST API. Tags can be accessed through the note JSON response when getting file contents."), nil
-}
+	if operation == "set" {
+		if err := backend.SetFrontmatter(filepath, field, value); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to set frontmatter: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Frontmatter field '%s' set to %s for file %s", field, formatFrontmatterValue(value), filepath)), nil
+	}
 
-// GetFrontmatter gets frontmatter from a file
-func GetFrontmatter(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	filepath, err := req.RequireString("filepath")
+	content, err := backend.GetFileContents(filepath)
 	if err != nil {
-		return mcp.NewToolResultError("filepath parameter required"), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filepath, err)), nil
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
 	}
 
-	result, err := obsidianClient.GetFrontmatter(filepath)
+	var updated, message string
+	switch operation {
+	case "add":
+		updated, err = frontmatter.AddToList(content, field, values)
+		message = fmt.Sprintf("Added %s to frontmatter list '%s' in %s", formatFrontmatterValue(value), field, filepath)
+	case "remove":
+		updated, err = frontmatter.RemoveFromList(content, field, values)
+		message = fmt.Sprintf("Removed %s from frontmatter list '%s' in %s", formatFrontmatterValue(value), field, filepath)
+	case "delete":
+		updated, err = frontmatter.DeleteField(content, field)
+		message = fmt.Sprintf("Frontmatter field '%s' deleted from %s", field, filepath)
+	}
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get frontmatter: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to %s frontmatter: %v", operation, err)), nil
 	}
 
-	jsonData, err := json.Marshal(result.Data)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal frontmatter: %v", err)), nil
+	if err := backend.PutContent(filepath, updated); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to write %s: %v", filepath, err)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Frontmatter for %s: %s", filepath, string(jsonData))), nil
+	return mcp.NewToolResultText(message), nil
 }
 
-// SetFrontmatter sets frontmatter for a file
-func SetFrontmatter(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	filepath, err := req.RequireString("filepath")
-	if err != nil {
-		return mcp.NewToolResultError("filepath parameter required"), nil
+// frontmatterArgument returns a typed tool argument: strings are decoded as
+// JSON when possible, values already sent as JSON are used as they are
+func frontmatterArgument(args map[string]interface{}, name string) (interface{}, bool) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return nil, false
 	}
-
-	field, err := req.RequireString("field")
-	if err != nil {
-		return mcp.NewToolResultError("field parameter required"), nil
+	if text, ok := raw.(string); ok {
+		return frontmatter.DecodeValue(text), true
 	}
+	return raw, true
+}
 
-	value, err := req.RequireString("value")
-	if err != nil {
-		return mcp.NewToolResultError("value parameter required"), nil
-	}
+// frontmatterData reads the data argument of SetFrontmatter as fields in the
+// order given. A JSON string keeps its key order; an object the client sent
+// as JSON has already lost it, so its keys are sorted.
+func frontmatterData(data interface{}) ([]frontmatter.Field, error) {
+	errNotObject := fmt.Errorf("data parameter must be a JSON object of field names to values")
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+	var fields []frontmatter.Field
+	switch data := data.(type) {
+	case map[string]interface{}:
+		for name, value := range data {
+			fields = append(fields, frontmatter.Field{Key: name, Value: value})
+		}
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	case string:
+		decoder := json.NewDecoder(strings.NewReader(data))
+		if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+			return nil, errNotObject
+		}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, fmt.Errorf("invalid data parameter: %v", err)
+			}
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				return nil, fmt.Errorf("invalid data parameter: %v", err)
+			}
+			fields = appendField(fields, token.(string), value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("invalid data parameter: %v", err)
+		}
+		if _, err := decoder.Token(); err != io.EOF {
+			return nil, errNotObject
+		}
+	default:
+		return nil, errNotObject
 	}
 
-	err = obsidianClient.SetFrontmatter(filepath, field, value)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to set frontmatter: %v", err)), nil
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("data parameter must set at least one field")
 	}
+	return fields, nil
+}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Frontmatter field '%s' set to '%s' for file %s", field, value, filepath)), nil
+// appendField adds a field, or replaces the value of an earlier field with
+// the same key so that the last value wins as in a JSON object
+func appendField(fields []frontmatter.Field, key string, value interface{}) []frontmatter.Field {
+	for i := range fields {
+		if fields[i].Key == key {
+			fields[i].Value = value
+			return fields
+		}
+	}
+	return append(fields, frontmatter.Field{Key: key, Value: value})
 }
 
-// GetBlockReference gets a block reference from a file
-func GetBlockReference(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return mcp.NewToolResultError("Block reference endpoint is not available in this version of the Obsidian Local REST API. Use the PATCH endpoint with Target-Type: block to work with block references."), nil
+// formatFrontmatterValue renders a frontmatter value as JSON for messages
+func formatFrontmatterValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
 }
 
 // GetHeadings gets all headings from a markdown file
@@ -657,12 +713,12 @@ func GetHeadings(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 		return mcp.NewToolResultError("filepath parameter required"), nil
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	content, err := obsidianClient.GetFileContents(filePath)
+	content, err := backend.GetFileContents(filePath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
 	}
@@ -705,12 +761,12 @@ func GetHeadingContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		}
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	content, err := obsidianClient.GetFileContents(filePath)
+	content, err := backend.GetFileContents(filePath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
 	}
@@ -755,52 +811,26 @@ func parseHeadings(content string) []types.HeadingInfo {
 	var headings []types.HeadingInfo
 	lines := strings.Split(content, "\n")
 
-	var currentHeading *types.HeadingInfo
-	var currentContent strings.Builder
-
-	for i, line := range lines {
-		lineNum := i + 1
-		trimmedLine := strings.TrimSpace(line)
-
-		// Check if this is a heading
-		if strings.HasPrefix(trimmedLine, "#") {
-			// If we have a current heading, save it
-			if currentHeading != nil {
-				currentHeading.Content = strings.TrimSpace(currentContent.String())
-				headings = append(headings, *currentHeading)
-			}
-
-			// Parse the new heading
-			level := 0
-			title := trimmedLine
-			for strings.HasPrefix(title, "#") {
-				level++
-				title = strings.TrimPrefix(title, "#")
-			}
-			title = strings.TrimSpace(title)
-
-			// Create new heading
-			currentHeading = &types.HeadingInfo{
-				Level: level,
-				Title: title,
-				Line:  lineNum,
-			}
-
-			// Reset content builder
-			currentContent.Reset()
-		} else if currentHeading != nil {
-			// Add line to current heading's content
-			if currentContent.Len() > 0 {
-				currentContent.WriteString("\n")
-			}
-			currentContent.WriteString(line)
+	var elements []types.MarkdownElement
+	for _, element := range markdown.Parse(content) {
+		if element.Type == markdown.TypeHeading {
+			elements = append(elements, element)
 		}
 	}
 
-	// Don't forget the last heading
-	if currentHeading != nil {
-		currentHeading.Content = strings.TrimSpace(currentContent.String())
-		headings = append(headings, *currentHeading)
+	for i, element := range elements {
+		// A heading's content runs until the next heading
+		end := len(lines)
+		if i+1 < len(elements) {
+			end = elements[i+1].Line - 1
+		}
+
+		headings = append(headings, types.HeadingInfo{
+			Level:   element.Level,
+			Title:   element.Title,
+			Content: strings.TrimSpace(strings.Join(lines[element.EndLine:end], "\n")),
+			Line:    element.Line,
+		})
 	}
 
 	return headings
@@ -833,7 +863,7 @@ func selectElements(elements []types.MarkdownElement, selectorType, query string
 				}
 			}
 		case "code":
-			if element.Type == "code_block" {
+			if element.Type == "code_block" || element.Type == "mermaid" || element.Type == "math_block" {
 				if query == "" || matchesQuery(element.Content, query, exact) {
 					selected = append(selected, element)
 				}
@@ -862,253 +892,126 @@ func matchesQuery(content, query string, exact bool) bool {
 	return strings.Contains(strings.ToLower(content), strings.ToLower(query))
 }
 
-// parseMarkdownElements parses markdown content and extracts all elements
-func parseMarkdownElements(content string) []types.MarkdownElement {
-	var elements []types.MarkdownElement
-	lines := strings.Split(content, "\n")
+// resolveHeadingTarget matches a heading target such as "Parent::Child"
+// against the note's headings, trying exact then case-insensitive paths and
+// finally a unique bare title. It returns the matching heading path as written
+// in the note, or "" together with every available heading path.
+func resolveHeadingTarget(elements []types.MarkdownElement, target string) (string, []string) {
+	parts := strings.Split(target, patch.HeadingDelimiter)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
 
-	var currentElement *types.MarkdownElement
-	var currentContent strings.Builder
-	var inCodeBlock bool
-
-	for i, line := range lines {
-		lineNum := i + 1
-		trimmedLine := strings.TrimSpace(line)
-
-		// Check for code block start/end
-		if strings.HasPrefix(trimmedLine, "```") {
-			if inCodeBlock {
-				// End of code block
-				if currentElement != nil {
-					currentElement.Content = strings.TrimSpace(currentContent.String())
-					elements = append(elements, *currentElement)
-				}
-				inCodeBlock = false
-				currentElement = nil
-				currentContent.Reset()
-			} else {
-				// Start of code block
-				if currentElement != nil {
-					currentElement.Content = strings.TrimSpace(currentContent.String())
-					elements = append(elements, *currentElement)
-				}
-				language := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "```"))
-				currentElement = &types.MarkdownElement{
-					Type:       "code_block",
-					Line:       lineNum,
-					Attributes: map[string]string{"language": language},
-				}
-				inCodeBlock = true
-				currentContent.Reset()
-			}
+	var paths [][]string
+	var stack []types.MarkdownElement
+	for _, element := range elements {
+		if element.Type != "heading" {
 			continue
 		}
-
-		if inCodeBlock {
-			if currentContent.Len() > 0 {
-				currentContent.WriteString("\n")
-			}
-			currentContent.WriteString(line)
-			continue
+		for len(stack) > 0 && stack[len(stack)-1].Level >= element.Level {
+			stack = stack[:len(stack)-1]
 		}
+		stack = append(stack, element)
 
-		// Check for headings
-		if strings.HasPrefix(trimmedLine, "#") {
-			if currentElement != nil {
-				currentElement.Content = strings.TrimSpace(currentContent.String())
-				elements = append(elements, *currentElement)
-			}
-
-			level := 0
-			title := trimmedLine
-			for strings.HasPrefix(title, "#") {
-				level++
-				title = strings.TrimPrefix(title, "#")
-			}
-			title = strings.TrimSpace(title)
-
-			currentElement = &types.MarkdownElement{
-				Type:  "heading",
-				Level: level,
-				Title: title,
-				Line:  lineNum,
-			}
-			currentContent.Reset()
-			continue
-		}
-
-		// Check for lists
-		if strings.HasPrefix(trimmedLine, "- ") || strings.HasPrefix(trimmedLine, "* ") || strings.HasPrefix(trimmedLine, "+ ") {
-			if currentElement != nil && currentElement.Type != "list" {
-				currentElement.Content = strings.TrimSpace(currentContent.String())
-				elements = append(elements, *currentElement)
-			}
-
-			if currentElement == nil || currentElement.Type != "list" {
-				currentElement = &types.MarkdownElement{
-					Type: "list",
-					Line: lineNum,
-				}
-				currentContent.Reset()
-			}
-
-			if currentContent.Len() > 0 {
-				currentContent.WriteString("\n")
-			}
-			currentContent.WriteString(trimmedLine)
-			continue
+		path := make([]string, len(stack))
+		for i, heading := range stack {
+			path[i] = heading.Title
 		}
+		paths = append(paths, path)
+	}
 
-		// Check for tables
-		if strings.Contains(trimmedLine, "|") && strings.Count(trimmedLine, "|") >= 2 {
-			if currentElement != nil && currentElement.Type != "table" {
-				currentElement.Content = strings.TrimSpace(currentContent.String())
-				elements = append(elements, *currentElement)
+	matchPath := func(equal func(a, b string) bool) []string {
+		for _, path := range paths {
+			if len(path) != len(parts) {
+				continue
 			}
-
-			if currentElement == nil || currentElement.Type != "table" {
-				currentElement = &types.MarkdownElement{
-					Type: "table",
-					Line: lineNum,
+			matched := true
+			for i := range path {
+				if !equal(path[i], parts[i]) {
+					matched = false
+					break
 				}
-				currentContent.Reset()
 			}
-
-			if currentContent.Len() > 0 {
-				currentContent.WriteString("\n")
+			if matched {
+				return path
 			}
-			currentContent.WriteString(trimmedLine)
-			continue
 		}
+		return nil
+	}
 
-		// Check for blockquotes
-		if strings.HasPrefix(trimmedLine, ">") {
-			if currentElement != nil && currentElement.Type != "blockquote" {
-				currentElement.Content = strings.TrimSpace(currentContent.String())
-				elements = append(elements, *currentElement)
-			}
-
-			if currentElement == nil || currentElement.Type != "blockquote" {
-				currentElement = &types.MarkdownElement{
-					Type: "blockquote",
-					Line: lineNum,
+	exact := func(a, b string) bool { return a == b }
+	path := matchPath(exact)
+	if path == nil {
+		path = matchPath(strings.EqualFold)
+	}
+	if path == nil && len(parts) == 1 {
+		for _, equal := range []func(a, b string) bool{exact, strings.EqualFold} {
+			var matches [][]string
+			for _, candidate := range paths {
+				if equal(candidate[len(candidate)-1], parts[0]) {
+					matches = append(matches, candidate)
 				}
-				currentContent.Reset()
-			}
-
-			if currentContent.Len() > 0 {
-				currentContent.WriteString("\n")
-			}
-			currentContent.WriteString(trimmedLine)
-			continue
-		}
-
-		// Check for links and images
-		if strings.Contains(trimmedLine, "[") && strings.Contains(trimmedLine, "]") {
-			if currentElement != nil {
-				currentElement.Content = strings.TrimSpace(currentContent.String())
-				elements = append(elements, *currentElement)
 			}
-
-			elementType := "link"
-			if strings.Contains(trimmedLine, "![") {
-				elementType = "image"
-			}
-
-			currentElement = &types.MarkdownElement{
-				Type:  elementType,
-				Line:  lineNum,
-				Title: extractLinkTitle(trimmedLine),
+			if len(matches) == 1 {
+				path = matches[0]
+				break
 			}
-			currentContent.Reset()
-			continue
 		}
+	}
 
-		// Regular paragraph content
-		if trimmedLine != "" {
-			if currentElement == nil || currentElement.Type != "paragraph" {
-				if currentElement != nil {
-					currentElement.Content = strings.TrimSpace(currentContent.String())
-					elements = append(elements, *currentElement)
-				}
-				currentElement = &types.MarkdownElement{
-					Type: "paragraph",
-					Line: lineNum,
-				}
-				currentContent.Reset()
-			}
-
-			if currentContent.Len() > 0 {
-				currentContent.WriteString("\n")
-			}
-			currentContent.WriteString(line)
-		} else {
-			// Empty line - end current paragraph
-			if currentElement != nil && currentElement.Type == "paragraph" {
-				currentElement.Content = strings.TrimSpace(currentContent.String())
-				elements = append(elements, *currentElement)
-				currentElement = nil
-				currentContent.Reset()
-			}
-		}
+	if path != nil {
+		return strings.Join(path, patch.HeadingDelimiter), nil
 	}
 
-	// Don't forget the last element
-	if currentElement != nil {
-		currentElement.Content = strings.TrimSpace(currentContent.String())
-		elements = append(elements, *currentElement)
+	available := make([]string, len(paths))
+	for i, candidate := range paths {
+		available[i] = strings.Join(candidate, patch.HeadingDelimiter)
 	}
+	return "", available
+}
 
-	return elements
+// parseMarkdownElements parses markdown content and extracts all top-level blocks
+func parseMarkdownElements(content string) []types.MarkdownElement {
+	return markdown.Parse(content)
 }
 
-// buildNestedStructure builds a nested structure from flat elements
+// buildNestedStructure builds a nested structure from flat elements: every
+// heading owns the blocks and deeper headings that follow it
 func buildNestedStructure(elements []types.MarkdownElement) []types.NestedElement {
-	var nestedElements []types.NestedElement
-	var headingStack []*types.NestedElement
+	nestedElements, _ := nestUnder(elements, 0, 0, nil)
+	return nestedElements
+}
+
+// nestUnder collects elements starting at index i that belong under a heading
+// of the given level, returning them and the index of the first element that
+// does not
+func nestUnder(elements []types.MarkdownElement, i, level int, parentPath []string) ([]types.NestedElement, int) {
+	var nested []types.NestedElement
+
+	for i < len(elements) {
+		element := elements[i]
+		if element.Type == "heading" && element.Level <= level {
+			break
+		}
+
+		path := make([]string, len(parentPath), len(parentPath)+1)
+		copy(path, parentPath)
+		path = append(path, fmt.Sprintf("%s:%s", element.Type, element.Title))
 
-	for _, element := range elements {
 		nestedElement := types.NestedElement{
 			Element: element,
 			Level:   element.Level,
-			Path:    []string{fmt.Sprintf("%s:%s", element.Type, element.Title)},
+			Path:    path,
 		}
 
+		i++
 		if element.Type == "heading" {
-			// This is a heading - find the appropriate parent heading
-			for len(headingStack) > 0 && headingStack[len(headingStack)-1].Element.Level >= element.Level {
-				headingStack = headingStack[:len(headingStack)-1]
-			}
-
-			if len(headingStack) > 0 {
-				// Add to parent heading's children
-				parent := headingStack[len(headingStack)-1]
-				parent.Children = append(parent.Children, nestedElement)
-				nestedElement.Path = append(parent.Path, nestedElement.Path[0])
-				// Add to stack
-				headingStack = append(headingStack, &parent.Children[len(parent.Children)-1])
-			} else {
-				// Top-level heading
-				nestedElements = append(nestedElements, nestedElement)
-				// Add to stack
-				headingStack = append(headingStack, &nestedElements[len(nestedElements)-1])
-			}
-
-		} else {
-			// This is content - add to the current heading or top level
-			if len(headingStack) > 0 {
-				// Add to the most recent heading's children
-				currentHeading := headingStack[len(headingStack)-1]
-				currentHeading.Children = append(currentHeading.Children, nestedElement)
-				nestedElement.Path = append(currentHeading.Path, nestedElement.Path[0])
-			} else {
-				// No current heading - add to top level
-				nestedElements = append(nestedElements, nestedElement)
-			}
+			nestedElement.Children, i = nestUnder(elements, i, element.Level, path)
 		}
+		nested = append(nested, nestedElement)
 	}
 
-	return nestedElements
+	return nested, i
 }
 
 // selectNestedElements selects elements based on nested structure
@@ -1244,12 +1147,12 @@ func DiscoverMarkdownStructure(ctx context.Context, req mcp.CallToolRequest) (*m
 		}
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	content, err := obsidianClient.GetFileContents(filePath)
+	content, err := backend.GetFileContents(filePath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
 	}
@@ -1259,8 +1162,9 @@ func DiscoverMarkdownStructure(ctx context.Context, req mcp.CallToolRequest) (*m
 
 	// Create simplified JSON structure
 	structureData := map[string]interface{}{
-		"filepath": filePath,
-		"headings": buildSimpleHeadingsJSON(nestedElements, maxDepth),
+		"filepath":          filePath,
+		"headings":          buildSimpleHeadingsJSON(nestedElements, maxDepth),
+		"obsidian_elements": buildObsidianElementsJSON(elements),
 	}
 
 	// Convert to JSON with proper encoding
@@ -1501,12 +1405,11 @@ func buildFrontmatterTargetsJSON(elements []types.NestedElement, maxDepth int) [
 
 	for _, element := range elements {
 		if element.Element.Type == "frontmatter" {
-			fields := extractFrontmatterFields(element.Element.Content)
-			for fieldName, fieldValue := range fields {
+			for _, field := range extractFrontmatterFields(element.Element) {
 				frontmatter = append(frontmatter, map[string]interface{}{
-					"field": fieldName,
-					"value": fieldValue,
-					"line":  element.Element.Line,
+					"field": field.Key,
+					"value": field.Value,
+					"line":  field.Line,
 				})
 			}
 		}
@@ -1578,6 +1481,37 @@ func buildSimpleHeadingsJSON(elements []types.NestedElement, maxDepth int) []str
 	return headings
 }
 
+// buildObsidianElementsJSON lists the Obsidian-specific elements of a note
+// (callouts, embeds, wikilinks, comments, highlights, math, footnotes and
+// mermaid diagrams), including those nested in lists, quotes and paragraphs
+func buildObsidianElementsJSON(elements []types.MarkdownElement) []map[string]interface{} {
+	obsidianElements := []map[string]interface{}{}
+
+	markdown.Walk(elements, func(element types.MarkdownElement) {
+		if !markdown.IsObsidianType(element.Type) {
+			return
+		}
+
+		item := map[string]interface{}{
+			"type": element.Type,
+			"line": element.Line,
+		}
+		if element.EndLine > element.Line {
+			item["end_line"] = element.EndLine
+		}
+		if element.Title != "" {
+			item["title"] = element.Title
+		}
+		if len(element.Attributes) > 0 {
+			item["attributes"] = element.Attributes
+		}
+
+		obsidianElements = append(obsidianElements, item)
+	})
+
+	return obsidianElements
+}
+
 // getContentPreview returns the full content of the element (no longer truncated)
 func getContentPreview(element types.MarkdownElement) string {
 	if element.Content == "" {
@@ -1632,6 +1566,24 @@ func getElementDescription(element types.MarkdownElement) string {
 		return "External or internal link"
 	case "image":
 		return "Image or media file"
+	case "callout":
+		return fmt.Sprintf("Callout (%s)", element.Attributes["callout_type"])
+	case "embed":
+		return fmt.Sprintf("Embedded %s", element.Attributes["kind"])
+	case "wikilink":
+		return "Internal wikilink"
+	case "comment":
+		return "Obsidian comment"
+	case "math_block":
+		return "Math block"
+	case "footnote":
+		return "Footnote definition"
+	case "mermaid":
+		return "Mermaid diagram"
+	case "thematic_break":
+		return "Horizontal rule"
+	case "html_block":
+		return "HTML block"
 	default:
 		return "Content element"
 	}
@@ -1703,9 +1655,8 @@ func matchesSelector(element types.NestedElement, selectorType, query string, le
 				return true
 			}
 			// Check if any frontmatter field matches the query
-			fields := extractFrontmatterFields(element.Element.Content)
-			for fieldName, fieldValue := range fields {
-				if matchesQuery(fieldName, query, exact) || matchesQuery(fieldValue, query, exact) {
+			for _, field := range extractFrontmatterFields(element.Element) {
+				if matchesQuery(field.Key, query, exact) || matchesQuery(frontmatter.FormatValue(field.Value), query, exact) {
 					return true
 				}
 			}
@@ -1760,12 +1711,12 @@ func ReadMarkdownContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 		}
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	content, err := obsidianClient.GetFileContents(filePath)
+	content, err := backend.GetFileContents(filePath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
 	}
@@ -1895,19 +1846,6 @@ func getElementIcon(elementType string) string {
 	}
 }
 
-// extractLinkTitle extracts the title from a markdown link or image
-func extractLinkTitle(line string) string {
-	// Simple extraction - look for [title](url) pattern
-	if strings.Contains(line, "[") && strings.Contains(line, "]") {
-		start := strings.Index(line, "[")
-		end := strings.Index(line, "]")
-		if start >= 0 && end > start {
-			return strings.TrimSpace(line[start+1 : end])
-		}
-	}
-	return ""
-}
-
 // getHeadingLevelDescription returns a description for a heading level
 func getHeadingLevelDescription(level int) string {
 	switch level {
@@ -1938,12 +1876,12 @@ func GetNestedContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return mcp.NewToolResultError("nested_path parameter required"), nil
 	}
 
-	obsidianClient, err := client.NewObsidianClientFromEnv()
+	backend, err := getBackend()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create Obsidian client: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
 	}
 
-	content, err := obsidianClient.GetFileContents(filePath)
+	content, err := backend.GetFileContents(filePath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
 	}
@@ -2211,9 +2149,8 @@ func printFrontmatterTargets(buf *strings.Builder, elements []types.NestedElemen
 	for _, element := range elements {
 		if element.Element.Type == "frontmatter" {
 			// Extract frontmatter fields
-			fields := extractFrontmatterFields(element.Element.Content)
-			for fieldName := range fields {
-				fmt.Fprintf(buf, "• %s: `%s`\n", fieldName, fieldName)
+			for _, field := range extractFrontmatterFields(element.Element) {
+				fmt.Fprintf(buf, "• %s: `%s`\n", field.Key, field.Key)
 			}
 		}
 	}
@@ -2225,11 +2162,10 @@ func printFrontmatterTargetsStructured(buf *strings.Builder, elements []types.Ne
 	for _, element := range elements {
 		if element.Element.Type == "frontmatter" {
 			// Extract frontmatter fields
-			fields := extractFrontmatterFields(element.Element.Content)
-			for fieldName, fieldValue := range fields {
-				fmt.Fprintf(buf, "- field: \"%s\"\n", fieldName)
-				fmt.Fprintf(buf, "  value: \"%s\"\n", fieldValue)
-				fmt.Fprintf(buf, "  line: %d\n", element.Element.Line)
+			for _, field := range extractFrontmatterFields(element.Element) {
+				fmt.Fprintf(buf, "- field: \"%s\"\n", field.Key)
+				fmt.Fprintf(buf, "  value: \"%s\"\n", frontmatter.FormatValue(field.Value))
+				fmt.Fprintf(buf, "  line: %d\n", field.Line)
 			}
 		}
 	}
@@ -2237,6 +2173,10 @@ func printFrontmatterTargetsStructured(buf *strings.Builder, elements []types.Ne
 
 // extractBlockID tries to extract a block ID from an element
 func extractBlockID(element types.MarkdownElement) string {
+	if id := element.Attributes["block_id"]; id != "" {
+		return id
+	}
+
 	// Look for block reference patterns like ^2d9b4a
 	lines := strings.Split(element.Content, "\n")
 	for _, line := range lines {
@@ -2254,25 +2194,17 @@ func extractBlockID(element types.MarkdownElement) string {
 	return ""
 }
 
-// extractFrontmatterFields extracts frontmatter field names from content
-func extractFrontmatterFields(content string) map[string]string {
-	fields := make(map[string]string)
-
-	// Simple frontmatter parsing - look for YAML-like key-value pairs
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, ":") && !strings.HasPrefix(line, "#") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				fieldName := strings.TrimSpace(parts[0])
-				if fieldName != "" {
-					fields[fieldName] = strings.TrimSpace(parts[1])
-				}
-			}
-		}
+// extractFrontmatterFields decodes the YAML of a frontmatter element into
+// its fields in document order, with line numbers relative to the note
+func extractFrontmatterFields(element types.MarkdownElement) []frontmatter.Field {
+	fields, err := frontmatter.Fields(element.Content)
+	if err != nil {
+		return nil
 	}
 
+	for i := range fields {
+		fields[i].Line += element.Line
+	}
 	return fields
 }
 
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"mcp-obsidian/obsidian/client"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// getOpenFileBackend returns the injected backend if it can open notes in the Obsidian UI
+func getOpenFileBackend() (client.OpenFileBackend, error) {
+	backend, err := getBackend()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vault backend: %w", err)
+	}
+
+	opener, ok := backend.(client.OpenFileBackend)
+	if !ok {
+		return nil, fmt.Errorf("opening files is not supported by the configured vault backend")
+	}
+
+	return opener, nil
+}
+
+// OpenFile opens a note in the Obsidian user interface
+func OpenFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath, err := req.RequireString("filepath")
+	if err != nil {
+		return mcp.NewToolResultError("filepath parameter required"), nil
+	}
+
+	newLeaf := req.GetBool("new_leaf", false)
+
+	opener, err := getOpenFileBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := opener.OpenFile(filePath, newLeaf); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to open %s: %v", filePath, err)), nil
+	}
+
+	if newLeaf {
+		return mcp.NewToolResultText(fmt.Sprintf("📂 Opened %s in a new leaf", filePath)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("📂 Opened %s", filePath)), nil
+}
+
+// openAfterWrite opens filePath in Obsidian when the request sets open_after and
+// returns a status line for the tool result. The write has already succeeded,
+// so a failure to open is reported rather than turned into a tool error.
+func openAfterWrite(req mcp.CallToolRequest, filePath string) string {
+	if !req.GetBool("open_after", false) {
+		return ""
+	}
+
+	opener, err := getOpenFileBackend()
+	if err != nil {
+		return fmt.Sprintf("\n\n⚠️ Could not open %s: %v", filePath, err)
+	}
+
+	if err := opener.OpenFile(filePath, req.GetBool("new_leaf", false)); err != nil {
+		return fmt.Sprintf("\n\n⚠️ Could not open %s: %v", filePath, err)
+	}
+
+	return fmt.Sprintf("\n\n📂 Opened %s in Obsidian", filePath)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"mcp-obsidian/obsidian/patch"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// patchInServer applies a lines or regex patch by reading the note, patching
+// it here and writing it back, since the Local REST API has no such targets
+func patchInServer(req mcp.CallToolRequest, filePath, operation, targetType, target, content string) (*mcp.CallToolResult, error) {
+	switch operation {
+	case "append", "prepend", "replace", "delete":
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid operation: %s. Must be one of: append, prepend, replace, delete", operation)), nil
+	}
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	existing, err := backend.GetFileContents(filePath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
+	}
+
+	var updated, detail string
+	switch targetType {
+	case "lines":
+		updated, err = patch.ApplyLines(existing, operation, target, req.GetString("expected_hash", ""), content)
+		if errors.Is(err, patch.ErrHashMismatch) {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to patch content in %s: %v. Current content of the lines:\n%s", filePath, err, numberedLines(existing, target))), nil
+		}
+		if err == nil {
+			detail = patchedLinesHash(updated, operation, target, content)
+		}
+	case "regex":
+		occurrence := req.GetInt("occurrence", 1)
+		updated, err = patch.ApplyRegex(existing, operation, target, occurrence, content)
+		detail = fmt.Sprintf("🔢 Occurrence: %d\n", occurrence)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to patch content in %s: %v", filePath, err)), nil
+	}
+
+	if err := backend.PutContent(filePath, updated); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to write %s: %v", filePath, err)), nil
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "✅ Successfully patched content in %s\n\n", filePath)
+	fmt.Fprintf(&buf, "📝 Operation: %s\n", operation)
+	fmt.Fprintf(&buf, "🎯 Target Type: %s\n", targetType)
+	fmt.Fprintf(&buf, "🎯 Target: %s\n", target)
+	buf.WriteString(detail)
+	if operation != "delete" {
+		fmt.Fprintf(&buf, "📄 Content:\n%s", content)
+	}
+	buf.WriteString(openAfterWrite(req, filePath))
+
+	return mcp.NewToolResultText(buf.String()), nil
+}
+
+// patchedLinesHash reports the range and hash of the lines a lines patch
+// wrote, so a follow-up patch can be guarded with expected_hash
+func patchedLinesHash(updated, operation, target, content string) string {
+	if operation == "delete" {
+		return ""
+	}
+
+	start, end, _ := patch.ParseLineRange(target)
+	count := len(strings.Split(strings.TrimSuffix(content, "\n"), "\n"))
+	if operation == "append" {
+		start = end + 1
+	}
+	end = start + count - 1
+
+	lines := strings.Split(updated, "\n")
+	if end > len(lines) {
+		return ""
+	}
+	return fmt.Sprintf("🔒 Hash of %s: %s\n", patch.FormatLineRange(start, end), patch.LineHash(lines[start-1:end]))
+}
+
+// hashedLines returns a line range of content numbered, followed by the hash
+// a lines patch of that range takes as expected_hash
+func hashedLines(content, target string) (string, error) {
+	start, end, err := patch.ParseLineRange(target)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(content, "\n")
+	total := len(lines)
+	if total > 1 && lines[total-1] == "" {
+		// A trailing newline does not start another line
+		total--
+	}
+	if end > total {
+		return "", fmt.Errorf("%w: %s, the note has %d lines", patch.ErrTargetNotFound, patch.FormatLineRange(start, end), total)
+	}
+
+	hash := fmt.Sprintf("🔒 Hash of %s: %s\n", patch.FormatLineRange(start, end), patch.LineHash(lines[start-1:end]))
+	return numberedLines(content, target) + hash, nil
+}
+
+// numberedLines returns the lines of a range prefixed with their numbers
+func numberedLines(content, target string) string {
+	start, end, err := patch.ParseLineRange(target)
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(content, "\n")
+	var buf strings.Builder
+	for i := start; i <= end && i <= len(lines); i++ {
+		fmt.Fprintf(&buf, "%d: %s\n", i, strings.TrimSuffix(lines[i-1], "\r"))
+	}
+	return buf.String()
+}
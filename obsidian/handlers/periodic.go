@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/dates"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// getPeriodicBackend returns the injected backend if it supports periodic notes
+func getPeriodicBackend() (client.PeriodicNoteBackend, error) {
+	backend, err := getBackend()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vault backend: %w", err)
+	}
+
+	periodic, ok := backend.(client.PeriodicNoteBackend)
+	if !ok {
+		return nil, fmt.Errorf("periodic notes are not supported by the configured vault backend")
+	}
+
+	return periodic, nil
+}
+
+// dateOptions returns the time zone and week start configured for resolving relative dates
+func dateOptions() (dates.Options, error) {
+	config := client.LoadConfigFromEnv()
+	return dates.NewOptions(config.Timezone, config.WeekStart)
+}
+
+// resolvePeriodicDate turns a date expression such as "today", "last monday",
+// "2026-W14" or "2026-Q3" into a YYYY-MM-DD date inside the addressed period.
+// An empty expression is kept empty so the current-period endpoint is used.
+func resolvePeriodicDate(expr string) (string, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return "", nil
+	}
+
+	opts, err := dateOptions()
+	if err != nil {
+		return "", err
+	}
+
+	span, err := dates.Resolve(expr, time.Now(), opts)
+	if err != nil {
+		return "", err
+	}
+
+	return span.Date(), nil
+}
+
+// periodicLabel describes the addressed periodic note for tool output
+func periodicLabel(period, date string) string {
+	if date == "" {
+		return fmt.Sprintf("%s (current)", period)
+	}
+	return fmt.Sprintf("%s (%s)", period, date)
+}
+
+// GetPeriodicNote gets a periodic note for a date or the current period
+func GetPeriodicNote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	period, err := req.RequireString("period")
+	if err != nil {
+		return mcp.NewToolResultError("period parameter required"), nil
+	}
+
+	date, err := resolvePeriodicDate(req.GetString("date", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid date: %v", err)), nil
+	}
+
+	periodic, err := getPeriodicBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	note, err := periodic.GetPeriodicNote(period, date)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get periodic note: %v", err)), nil
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Periodic Note: %s\n\n", periodicLabel(period, date))
+	if note.Exists {
+		fmt.Fprintf(&buf, "Path: %s\n", note.Path)
+	}
+	fmt.Fprintf(&buf, "Exists: %t\n", note.Exists)
+	if note.Content != "" {
+		fmt.Fprintf(&buf, "\nContent:\n%s", note.Content)
+	}
+
+	return mcp.NewToolResultText(buf.String()), nil
+}
+
+// CreatePeriodicNote appends content to a periodic note, creating it if needed
+func CreatePeriodicNote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	period, err := req.RequireString("period")
+	if err != nil {
+		return mcp.NewToolResultError("period parameter required"), nil
+	}
+
+	content, err := req.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError("content parameter required"), nil
+	}
+
+	date, err := resolvePeriodicDate(req.GetString("date", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid date: %v", err)), nil
+	}
+
+	periodic, err := getPeriodicBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	note, err := periodic.CreatePeriodicNote(period, date, content)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create periodic note: %v", err)), nil
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Created Periodic Note: %s\n\n", periodicLabel(period, date))
+	fmt.Fprintf(&buf, "Path: %s\n", note.Path)
+	fmt.Fprintf(&buf, "Exists: %t\n", note.Exists)
+
+	return mcp.NewToolResultText(buf.String()), nil
+}
+
+// PutPeriodicNote replaces the content of a periodic note
+func PutPeriodicNote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	period, err := req.RequireString("period")
+	if err != nil {
+		return mcp.NewToolResultError("period parameter required"), nil
+	}
+
+	content, err := req.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError("content parameter required"), nil
+	}
+
+	date, err := resolvePeriodicDate(req.GetString("date", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid date: %v", err)), nil
+	}
+
+	periodic, err := getPeriodicBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := periodic.PutPeriodicNote(period, date, content); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to replace periodic note: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully replaced periodic note: %s", periodicLabel(period, date))), nil
+}
+
+// PatchPeriodicNote patches a periodic note relative to a heading, block or frontmatter field
+func PatchPeriodicNote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	period, err := req.RequireString("period")
+	if err != nil {
+		return mcp.NewToolResultError("period parameter required"), nil
+	}
+
+	operation, err := req.RequireString("operation")
+	if err != nil {
+		return mcp.NewToolResultError("operation parameter required"), nil
+	}
+
+	targetType, err := req.RequireString("target_type")
+	if err != nil {
+		return mcp.NewToolResultError("target_type parameter required"), nil
+	}
+
+	target, err := req.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target parameter required"), nil
+	}
+
+	content, err := req.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError("content parameter required"), nil
+	}
+
+	date, err := resolvePeriodicDate(req.GetString("date", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid date: %v", err)), nil
+	}
+
+	target, err = normalizePatchArgs(operation, targetType, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	periodic, err := getPeriodicBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := periodic.PatchPeriodicNote(period, date, operation, targetType, target, content); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to patch periodic note: %v", err)), nil
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "✅ Successfully patched periodic note: %s\n\n", periodicLabel(period, date))
+	fmt.Fprintf(&buf, "📝 Operation: %s\n", operation)
+	fmt.Fprintf(&buf, "🎯 Target Type: %s\n", targetType)
+	fmt.Fprintf(&buf, "🎯 Target: %s\n", target)
+	fmt.Fprintf(&buf, "📄 Content:\n%s", content)
+
+	return mcp.NewToolResultText(buf.String()), nil
+}
+
+// DeletePeriodicNote deletes a periodic note
+func DeletePeriodicNote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	period, err := req.RequireString("period")
+	if err != nil {
+		return mcp.NewToolResultError("period parameter required"), nil
+	}
+
+	if !req.GetBool("confirm", false) {
+		return mcp.NewToolResultError("confirm must be set to true to delete a periodic note"), nil
+	}
+
+	date, err := resolvePeriodicDate(req.GetString("date", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid date: %v", err)), nil
+	}
+
+	periodic, err := getPeriodicBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := periodic.DeletePeriodicNote(period, date); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete periodic note: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("🗑️ Successfully deleted periodic note: %s", periodicLabel(period, date))), nil
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetRecentChanges returns the most recently created or modified notes
+func GetRecentChanges(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := req.GetInt("limit", 10)
+	days := req.GetInt("days", 7)
+	folder := strings.Trim(strings.TrimSpace(req.GetString("folder", "")), "/")
+	extensions := parseExtensions(req.GetString("extensions", "md"))
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	var cutoff time.Time
+	if days > 0 {
+		cutoff = time.Now().AddDate(0, 0, -days)
+	}
+
+	changes, err := collectRecentChanges(backend, folder, cutoff)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get recent changes: %v", err)), nil
+	}
+
+	var filtered []types.ChangeInfo
+	for _, change := range changes {
+		if folder != "" && !strings.HasPrefix(change.Path, folder+"/") {
+			continue
+		}
+		if !matchesExtension(change.Path, extensions) {
+			continue
+		}
+		if !cutoff.IsZero() && change.ModifiedTime.Before(cutoff) {
+			continue
+		}
+		filtered = append(filtered, change)
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].ModifiedTime.After(filtered[j].ModifiedTime)
+	})
+
+	totalMatched := len(filtered)
+	if limit > 0 && len(filtered) > limit {
+		filtered = filtered[:limit]
+	}
+	if filtered == nil {
+		filtered = []types.ChangeInfo{}
+	}
+
+	structureData := map[string]interface{}{
+		"days":          days,
+		"limit":         limit,
+		"total_matched": totalMatched,
+		"changes":       filtered,
+	}
+	if folder != "" {
+		structureData["folder"] = folder
+	}
+
+	jsonData, err := json.MarshalIndent(structureData, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// collectRecentChanges gathers modification times for notes. Backends with
+// JsonLogic support filter by mtime server-side in a single query; otherwise
+// the directory listing is walked and only notes without timestamps are fetched.
+func collectRecentChanges(backend client.VaultBackend, folder string, cutoff time.Time) ([]types.ChangeInfo, error) {
+	if jsonSearch, ok := backend.(client.JSONSearchBackend); ok {
+		query := map[string]interface{}{"var": "stat"}
+		if !cutoff.IsZero() {
+			query = map[string]interface{}{
+				"if": []interface{}{
+					map[string]interface{}{">=": []interface{}{map[string]interface{}{"var": "stat.mtime"}, cutoff.UnixMilli()}},
+					map[string]interface{}{"var": "stat"},
+					false,
+				},
+			}
+		}
+
+		results, err := jsonSearch.SearchJSON(query)
+		if err == nil {
+			var changes []types.ChangeInfo
+			for _, result := range results {
+				stat, ok := result.Result.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				changes = append(changes, newChangeInfo(result.Filename, types.NoteStat{
+					Ctime: jsonNumber(stat["ctime"]),
+					Mtime: jsonNumber(stat["mtime"]),
+					Size:  jsonNumber(stat["size"]),
+				}, cutoff))
+			}
+			return changes, nil
+		}
+	}
+
+	files, err := walkVaultFiles(backend, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []types.ChangeInfo
+	var missing []string
+	for _, file := range files {
+		if file.ModifiedTime.IsZero() {
+			if isMarkdownFile(file.Path) {
+				missing = append(missing, file.Path)
+			}
+			continue
+		}
+
+		changeType := "modified"
+		if !file.CreatedTime.IsZero() && !cutoff.IsZero() && !file.CreatedTime.Before(cutoff) {
+			changeType = "created"
+		}
+		changes = append(changes, types.ChangeInfo{
+			Path:         file.Path,
+			Type:         changeType,
+			ModifiedTime: file.ModifiedTime,
+			Size:         file.Size,
+		})
+	}
+
+	for _, note := range fetchNotes(backend, missing) {
+		changes = append(changes, newChangeInfo(note.Path, note.Stat, cutoff))
+	}
+
+	return changes, nil
+}
+
+// newChangeInfo converts note stats into a ChangeInfo
+func newChangeInfo(filePath string, stat types.NoteStat, cutoff time.Time) types.ChangeInfo {
+	changeType := "modified"
+	if stat.Ctime > 0 && !cutoff.IsZero() && stat.Ctime >= cutoff.UnixMilli() {
+		changeType = "created"
+	}
+
+	return types.ChangeInfo{
+		Path:         filePath,
+		Type:         changeType,
+		ModifiedTime: time.UnixMilli(stat.Mtime),
+		Size:         stat.Size,
+	}
+}
+
+// jsonNumber converts a decoded JSON number to int64
+func jsonNumber(value interface{}) int64 {
+	if f, ok := value.(float64); ok {
+		return int64(f)
+	}
+	return 0
+}
+
+// parseExtensions parses a comma-separated extension list; "all" disables filtering
+func parseExtensions(value string) map[string]bool {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "all") || value == "*" {
+		return nil
+	}
+
+	extensions := make(map[string]bool)
+	for _, ext := range strings.Split(value, ",") {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext != "" {
+			extensions[ext] = true
+		}
+	}
+	return extensions
+}
+
+// matchesExtension reports whether filePath has one of the given extensions
+func matchesExtension(filePath string, extensions map[string]bool) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	return extensions[strings.ToLower(strings.TrimPrefix(path.Ext(filePath), "."))]
+}
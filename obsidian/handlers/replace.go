@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mcp-obsidian/obsidian/replace"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// FindReplace replaces literal text or regex matches across the notes of a folder or glob
+func FindReplace(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	find, err := req.RequireString("find")
+	if err != nil {
+		return mcp.NewToolResultError("find parameter required"), nil
+	}
+	replacement := req.GetString("replace", "")
+	useRegex := req.GetBool("regex", false)
+	folder := strings.Trim(req.GetString("folder", ""), "/")
+	include := parseGlobs(req.GetString("include", ""))
+	dryRun := req.GetBool("dry_run", true)
+	contextLines := req.GetInt("context_lines", 3)
+
+	scope, err := replace.ValidateScope(req.GetString("scope", replace.ScopeAll))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pattern, err := replace.Compile(find, useRegex, req.GetBool("case_sensitive", true), req.GetBool("whole_word", false))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts := replace.Options{
+		Pattern:     pattern,
+		Replacement: replacement,
+		Regex:       useRegex,
+		Scope:       scope,
+		SkipCode:    req.GetBool("skip_code", false),
+	}
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	listed, err := listVaultNotes(backend, folder)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list notes: %v", err)), nil
+	}
+	var paths []string
+	for _, note := range listed {
+		if len(include) == 0 || matchesAnyGlob(include, note) {
+			paths = append(paths, note)
+		}
+	}
+
+	notes, unreadable := fetchNotesReportingFailures(backend, paths)
+
+	files := []map[string]interface{}{}
+	total, failed := 0, 0
+	for _, note := range notes {
+		updated, count := replace.Apply(note.Content, opts)
+		if count == 0 || updated == note.Content {
+			continue
+		}
+
+		entry := map[string]interface{}{
+			"path":         note.Path,
+			"replacements": count,
+		}
+		if dryRun {
+			entry["diff"] = replace.Unified(note.Path, note.Content, updated, contextLines)
+		} else if err := backend.PutContent(note.Path, updated); err != nil {
+			// A failed write is reported with the file; the other notes are still updated
+			entry["error"] = err.Error()
+			files = append(files, entry)
+			failed++
+			continue
+		}
+		files = append(files, entry)
+		total += count
+	}
+
+	result := map[string]interface{}{
+		"find":               find,
+		"replace":            replacement,
+		"regex":              useRegex,
+		"scope":              scope,
+		"dry_run":            dryRun,
+		"notes_searched":     len(notes),
+		"files_changed":      len(files) - failed,
+		"total_replacements": total,
+		"files":              files,
+	}
+	if folder != "" {
+		result["folder"] = folder
+	}
+	if failed > 0 {
+		result["files_failed"] = failed
+	}
+	if len(unreadable) > 0 {
+		// Notes that could not be read were not searched
+		result["notes_unreadable"] = unreadable
+	}
+
+	return jsonResult(result)
+}
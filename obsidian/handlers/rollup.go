@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/dates"
+	"mcp-obsidian/obsidian/patch"
+	"mcp-obsidian/obsidian/tasks"
+	"mcp-obsidian/obsidian/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxRollupPeriods bounds how many periodic notes a single rollup may fetch
+const maxRollupPeriods = 400
+
+// periodGranularity maps periodic note periods to date granularities
+var periodGranularity = map[string]dates.Granularity{
+	"daily":     dates.Day,
+	"weekly":    dates.Week,
+	"monthly":   dates.Month,
+	"quarterly": dates.Quarter,
+	"yearly":    dates.Year,
+}
+
+// rollupSection is the digest entry for one periodic note
+type rollupSection struct {
+	Date     string   `json:"date"`
+	Path     string   `json:"path"`
+	Headings []string `json:"headings,omitempty"` // Selected headings found in the note
+	Content  string   `json:"content"`
+}
+
+// rollupTask is an open task carried forward across periodic notes
+type rollupTask struct {
+	Text        string `json:"text"`
+	Path        string `json:"path"` // Most recent note carrying the task open
+	Line        int    `json:"line"` // 1-based line in Path
+	FirstSeen   string `json:"firstSeen"`
+	LastSeen    string `json:"lastSeen"`
+	Occurrences int    `json:"occurrences"`
+	open        bool   // Whether the latest appearance of the task is open
+}
+
+// PeriodicRollup merges the periodic notes of a date range into one digest
+func PeriodicRollup(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	period := req.GetString("period", "daily")
+	fromExpr, err := req.RequireString("from")
+	if err != nil {
+		return mcp.NewToolResultError("from parameter required"), nil
+	}
+	toExpr := req.GetString("to", fromExpr)
+	headingFilter := parseHeadingFilter(req.GetString("headings", ""))
+	includeTasks := req.GetBool("include_tasks", true)
+	format := req.GetString("format", "json")
+
+	granularity, ok := periodGranularity[period]
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid period: %s. Must be one of: daily, weekly, monthly, quarterly, yearly", period)), nil
+	}
+
+	if format != "json" && format != "markdown" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid format: %s. Must be one of: json, markdown", format)), nil
+	}
+
+	opts, err := dateOptions()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid date configuration: %v", err)), nil
+	}
+
+	now := time.Now()
+	fromSpan, err := dates.Resolve(fromExpr, now, opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid from date: %v", err)), nil
+	}
+	toSpan, err := dates.Resolve(toExpr, now, opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid to date: %v", err)), nil
+	}
+	if toSpan.End.Before(fromSpan.Start) || toSpan.End.Equal(fromSpan.Start) {
+		return mcp.NewToolResultError("to must not be before from"), nil
+	}
+
+	spans, err := rollupPeriods(fromSpan.Start, toSpan.End, granularity, opts)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	periods := make([]string, len(spans))
+	for i, span := range spans {
+		periods[i] = span.Date()
+	}
+	from := spans[0].Date()
+	to := spans[len(spans)-1].LastDay().Format(dates.DateLayout)
+
+	periodic, err := getPeriodicBackend()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	notes, err := fetchPeriodicNotes(periodic.GetPeriodicNote, period, periods)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get periodic notes: %v", err)), nil
+	}
+
+	sections := []rollupSection{}
+	missing := []string{}
+	var openTasks []*rollupTask
+	taskIndex := make(map[string]*rollupTask)
+
+	for i, note := range notes {
+		date := periods[i]
+		if note == nil {
+			missing = append(missing, date)
+			continue
+		}
+
+		lines := strings.Split(strings.ReplaceAll(note.Content, "\r\n", "\n"), "\n")
+
+		section := rollupSection{Date: date, Path: note.Path}
+		if len(headingFilter) > 0 {
+			section.Headings, section.Content = selectHeadingSections(lines, headingFilter)
+		} else {
+			section.Content = strings.TrimSpace(strings.Join(lines[bodyStartLine(lines):], "\n"))
+		}
+		sections = append(sections, section)
+
+		if !includeTasks {
+			continue
+		}
+		// Later notes win, so a task completed after it was carried forward is dropped
+		for _, task := range tasks.Parse(note.Path, note.Content) {
+			key := strings.ToLower(task.Text)
+			open := isOpenTask(task)
+			existing, ok := taskIndex[key]
+			if !ok {
+				if !open {
+					continue
+				}
+				existing = &rollupTask{Text: task.Text, FirstSeen: date}
+				taskIndex[key] = existing
+				openTasks = append(openTasks, existing)
+			}
+			existing.open = open
+			if open {
+				existing.Path = note.Path
+				existing.Line = task.Line
+				existing.LastSeen = date
+				existing.Occurrences++
+			}
+		}
+	}
+
+	carried := []*rollupTask{}
+	for _, task := range openTasks {
+		if task.open {
+			carried = append(carried, task)
+		}
+	}
+
+	if format == "markdown" {
+		return mcp.NewToolResultText(renderRollupMarkdown(period, from, to, sections, missing, carried, includeTasks)), nil
+	}
+
+	structureData := map[string]interface{}{
+		"period":        period,
+		"from":          from,
+		"to":            to,
+		"notes_found":   len(sections),
+		"notes_missing": missing,
+		"sections":      sections,
+	}
+	if len(headingFilter) > 0 {
+		structureData["headings"] = headingFilter
+	}
+	if includeTasks {
+		structureData["open_tasks"] = carried
+	}
+
+	jsonData, err := json.MarshalIndent(structureData, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// rollupPeriods returns every period of the given granularity that overlaps [start, end)
+func rollupPeriods(start, end time.Time, granularity dates.Granularity, opts dates.Options) ([]dates.Span, error) {
+	var periods []dates.Span
+	for day := start; day.Before(end); {
+		span, err := dates.SpanFor(day, granularity, opts)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, span)
+		if len(periods) > maxRollupPeriods {
+			return nil, fmt.Errorf("date range covers more than %d periods, narrow it down", maxRollupPeriods)
+		}
+		day = span.End
+	}
+	return periods, nil
+}
+
+// fetchPeriodicNotes loads periodic notes with bounded concurrency. Notes
+// that do not exist are left nil; any other error is returned. Results keep
+// the order of periods.
+func fetchPeriodicNotes(get func(period, date string) (*types.PeriodicNoteResponse, error), period string, periods []string) ([]*types.PeriodicNoteResponse, error) {
+	notes := make([]*types.PeriodicNoteResponse, len(periods))
+	errs := make([]error, len(periods))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, noteFetchWorkers)
+	for i, date := range periods {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, date string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			note, err := get(period, date)
+			if err != nil {
+				if !errors.Is(err, client.ErrNotFound) {
+					errs[i] = fmt.Errorf("%s: %w", date, err)
+				}
+				return
+			}
+			if note.Exists {
+				notes[i] = note
+			}
+		}(i, date)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return notes, nil
+}
+
+// parseHeadingFilter splits a comma-separated heading list, accepting "## Log" or "Log"
+func parseHeadingFilter(value string) []string {
+	var headings []string
+	for _, heading := range strings.Split(value, ",") {
+		heading = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(heading), "#"))
+		if heading != "" {
+			headings = append(headings, heading)
+		}
+	}
+	return headings
+}
+
+// selectHeadingSections returns the sections under headings whose title matches
+// the filter (case-insensitive), each including its heading line
+func selectHeadingSections(lines []string, filter []string) ([]string, string) {
+	headings := patch.Headings(lines)
+
+	var found []string
+	var parts []string
+	for i, heading := range headings {
+		matched := false
+		for _, want := range filter {
+			if strings.EqualFold(heading.Title, want) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		end := len(lines)
+		for _, next := range headings[i+1:] {
+			if next.Level <= heading.Level {
+				end = next.Line
+				break
+			}
+		}
+
+		found = append(found, heading.Title)
+		parts = append(parts, strings.TrimSpace(strings.Join(lines[heading.Line:end], "\n")))
+	}
+
+	return found, strings.Join(parts, "\n\n")
+}
+
+// isOpenTask reports whether a task still needs doing: unchecked or in progress
+func isOpenTask(task types.Task) bool {
+	return task.StatusName == tasks.StatusTodo || task.StatusName == tasks.StatusInProgress
+}
+
+// bodyStartLine returns the index of the first line after a leading frontmatter block
+func bodyStartLine(lines []string) int {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if t := strings.TrimSpace(lines[i]); t == "---" || t == "..." {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// renderRollupMarkdown renders the digest as a single markdown document
+func renderRollupMarkdown(period, from, to string, sections []rollupSection, missing []string, tasks []*rollupTask, includeTasks bool) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "# %s rollup: %s → %s\n\n", strings.ToUpper(period[:1])+period[1:], from, to)
+	fmt.Fprintf(&buf, "Notes found: %d", len(sections))
+	if len(missing) > 0 {
+		fmt.Fprintf(&buf, " (missing: %s)", strings.Join(missing, ", "))
+	}
+	fmt.Fprintf(&buf, "\n")
+
+	for _, section := range sections {
+		fmt.Fprintf(&buf, "\n## %s\n\n", section.Date)
+		fmt.Fprintf(&buf, "Source: [[%s]]\n\n", strings.TrimSuffix(section.Path, ".md"))
+		if section.Content == "" {
+			fmt.Fprintf(&buf, "_No matching content._\n")
+			continue
+		}
+		fmt.Fprintf(&buf, "%s\n", section.Content)
+	}
+
+	if includeTasks {
+		fmt.Fprintf(&buf, "\n## Open tasks\n\n")
+		if len(tasks) == 0 {
+			fmt.Fprintf(&buf, "_No open tasks._\n")
+		}
+		for _, task := range tasks {
+			fmt.Fprintf(&buf, "- [ ] %s (since %s, [[%s]])\n", task.Text, task.FirstSeen, strings.TrimSuffix(task.Path, ".md"))
+		}
+	}
+
+	return buf.String()
+}
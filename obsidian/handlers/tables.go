@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/patch"
+	"mcp-obsidian/obsidian/tables"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ReadTable returns a Markdown table as JSON rows keyed by column header
+func ReadTable(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath, err := req.RequireString("filepath")
+	if err != nil {
+		return mcp.NewToolResultError("filepath parameter required"), nil
+	}
+	heading := req.GetString("heading", "")
+	index := req.GetInt("index", 1)
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	content, err := backend.GetFileContents(filePath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
+	}
+
+	table, err := findTable(content, heading, index)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return jsonResult(tableResult(filePath, content, table, index))
+}
+
+// UpdateTable edits the rows or columns of a Markdown table and writes it back with aligned pipes
+func UpdateTable(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath, err := req.RequireString("filepath")
+	if err != nil {
+		return mcp.NewToolResultError("filepath parameter required"), nil
+	}
+	operation, err := req.RequireString("operation")
+	if err != nil {
+		return mcp.NewToolResultError("operation parameter required"), nil
+	}
+	heading := req.GetString("heading", "")
+	index := req.GetInt("index", 1)
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	content, err := backend.GetFileContents(filePath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
+	}
+
+	table, err := findTable(content, heading, index)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	affected, err := editTable(req, &table, operation)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	newContent, err := writeTable(backend, filePath, content, table)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update table in %s: %v", filePath, err)), nil
+	}
+
+	// Line numbers of the rendered table come from the updated note
+	updated, err := findTable(newContent, heading, index)
+	if err != nil {
+		updated = table
+	}
+	result := tableResult(filePath, newContent, updated, index)
+	result["operation"] = operation
+	result["changed"] = newContent != content
+	if affected >= 0 {
+		result["rows_affected"] = affected
+	}
+
+	return jsonResult(result)
+}
+
+// editTable applies an update_table operation and returns the number of rows
+// it touched, or -1 for column operations and sorting
+func editTable(req mcp.CallToolRequest, table *tables.Table, operation string) (int, error) {
+	switch operation {
+	case "add_row":
+		values, err := parseTableValues(req.GetString("row", ""), "row")
+		if err != nil {
+			return 0, err
+		}
+		if len(values) == 0 {
+			return 0, fmt.Errorf("add_row requires row, a JSON object of column values")
+		}
+		return 1, table.AddRow(values)
+	case "update_rows":
+		values, err := parseTableValues(req.GetString("row", ""), "row")
+		if err != nil {
+			return 0, err
+		}
+		if len(values) == 0 {
+			return 0, fmt.Errorf("update_rows requires row, a JSON object of the column values to set")
+		}
+		rows, err := selectTableRows(req, *table)
+		if err != nil {
+			return 0, err
+		}
+		return len(rows), table.UpdateRows(rows, values)
+	case "delete_rows":
+		rows, err := selectTableRows(req, *table)
+		if err != nil {
+			return 0, err
+		}
+		table.DeleteRows(rows)
+		return len(rows), nil
+	case "add_column":
+		return -1, table.AddColumn(req.GetString("column", ""), req.GetString("value", ""))
+	case "delete_column":
+		return -1, table.DeleteColumn(req.GetString("column", ""))
+	case "rename_column":
+		return -1, table.RenameColumn(req.GetString("column", ""), req.GetString("new_name", ""))
+	case "sort":
+		return -1, table.Sort(req.GetString("column", ""), req.GetBool("descending", false))
+	default:
+		return 0, fmt.Errorf("invalid operation: %s. Must be one of: add_row, update_rows, delete_rows, add_column, delete_column, rename_column, sort", operation)
+	}
+}
+
+// selectTableRows returns the rows addressed by row_index or where
+func selectTableRows(req mcp.CallToolRequest, table tables.Table) ([]int, error) {
+	if rowIndex := req.GetInt("row_index", 0); rowIndex > 0 {
+		if rowIndex > len(table.Rows) {
+			return nil, fmt.Errorf("row_index %d is out of range, the table has %d rows", rowIndex, len(table.Rows))
+		}
+		return []int{rowIndex - 1}, nil
+	}
+
+	where, err := parseTableValues(req.GetString("where", ""), "where")
+	if err != nil {
+		return nil, err
+	}
+	if len(where) == 0 {
+		return nil, fmt.Errorf("row_index or where parameter required")
+	}
+
+	rows, err := table.Match(where)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no rows match where %s", req.GetString("where", ""))
+	}
+	return rows, nil
+}
+
+// parseTableValues parses a JSON object of column values; numbers and
+// booleans are written as they appear in JSON
+func parseTableValues(raw, param string) (map[string]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &object); err != nil {
+		return nil, fmt.Errorf("invalid %s parameter, expected a JSON object of column values: %v", param, err)
+	}
+
+	values := make(map[string]string, len(object))
+	for column, value := range object {
+		switch v := value.(type) {
+		case nil:
+			values[column] = ""
+		case string:
+			values[column] = v
+		case float64:
+			values[column] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			values[column] = strconv.FormatBool(v)
+		default:
+			encoded, _ := json.Marshal(v)
+			values[column] = string(encoded)
+		}
+	}
+	return values, nil
+}
+
+// findTable returns the index-th (1-based) table of a note, counting only
+// tables below heading when one is given
+func findTable(content, heading string, index int) (tables.Table, error) {
+	found := tables.Find(content)
+
+	scope := "the note"
+	if heading != "" {
+		resolved, headingPaths := resolveHeadingTarget(parseMarkdownElements(content), heading)
+		if resolved == "" {
+			return tables.Table{}, fmt.Errorf("heading '%s' not found or ambiguous in file, use a nested heading such as Parent::Child. Available headings: %v", heading, headingPaths)
+		}
+		start, end, err := patch.Section(strings.Split(content, "\n"), resolved)
+		if err != nil {
+			return tables.Table{}, err
+		}
+
+		var inSection []tables.Table
+		for _, table := range found {
+			if table.Line-1 >= start && table.Line-1 < end {
+				inSection = append(inSection, table)
+			}
+		}
+		found = inSection
+		scope = fmt.Sprintf("the section of heading '%s'", resolved)
+	}
+
+	if len(found) == 0 {
+		return tables.Table{}, fmt.Errorf("no table found in %s", scope)
+	}
+	if index < 1 || index > len(found) {
+		return tables.Table{}, fmt.Errorf("table index %d is out of range, %s has %d tables", index, scope, len(found))
+	}
+	return found[index-1], nil
+}
+
+// writeTable renders a table in place of its old lines and saves the note.
+// A table under a heading is written back by replacing the heading's
+// section through PatchContent; a table before the first heading replaces
+// the whole note. It returns the new note content.
+func writeTable(backend client.VaultBackend, filePath, content string, table tables.Table) (string, error) {
+	lines := strings.Split(content, "\n")
+	rendered := table.Render()
+	if strings.HasSuffix(lines[table.Line-1], "\r") {
+		// The last line keeps its own ending, which has no \r at the end of the file
+		for i := range rendered[:len(rendered)-1] {
+			rendered[i] += "\r"
+		}
+		if strings.HasSuffix(lines[table.EndLine-1], "\r") {
+			rendered[len(rendered)-1] += "\r"
+		}
+	}
+	newLines := append(append(append([]string{}, lines[:table.Line-1]...), rendered...), lines[table.EndLine:]...)
+	newContent := strings.Join(newLines, "\n")
+	if newContent == content {
+		return content, nil
+	}
+
+	var enclosing *patch.Heading
+	for _, heading := range patch.Headings(lines) {
+		if heading.Line >= table.Line-1 {
+			break
+		}
+		enclosing = &heading
+	}
+
+	if enclosing != nil {
+		target := strings.Join(enclosing.Path, patch.HeadingDelimiter)
+		start, end, err := patch.Section(newLines, target)
+		// Fall back to a full write when the target resolves to another heading with the same path
+		if err == nil && start == enclosing.EndLine+1 && end >= table.Line-1+len(rendered) {
+			section := newLines[start:end]
+			// A replace patch leaves one blank line before the next heading
+			if end < len(newLines) && len(section) > 0 && strings.TrimSpace(section[len(section)-1]) == "" {
+				section = section[:len(section)-1]
+			}
+			body := strings.Join(section, "\n") + "\n"
+			return newContent, backend.PatchContent(filePath, "replace", "heading", target, body)
+		}
+	}
+
+	return newContent, backend.PutContent(filePath, newContent)
+}
+
+// tableResult describes a table for the JSON output of the table tools
+func tableResult(filePath, content string, table tables.Table, index int) map[string]interface{} {
+	result := map[string]interface{}{
+		"filepath":   filePath,
+		"index":      index,
+		"line":       table.Line,
+		"end_line":   table.EndLine,
+		"columns":    table.Keys(),
+		"alignments": table.Alignments,
+		"rows":       table.Records(),
+		"total_rows": len(table.Rows),
+	}
+
+	var path []string
+	for _, heading := range patch.Headings(strings.Split(content, "\n")) {
+		if heading.Line >= table.Line-1 {
+			break
+		}
+		path = heading.Path
+	}
+	if path != nil {
+		result["heading"] = strings.Join(path, patch.HeadingDelimiter)
+	}
+	if table.BlockID != "" {
+		result["block_id"] = table.BlockID
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/tags"
+	"mcp-obsidian/obsidian/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetTags builds a vault-wide tag index with per-tag counts and files
+func GetTags(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	prefix := strings.ToLower(tags.Normalize(req.GetString("prefix", "")))
+	sortBy := req.GetString("sort", "count")
+	nested := req.GetBool("nested", true)
+	limit := req.GetInt("limit", 0)
+
+	if sortBy != "count" && sortBy != "name" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid sort: %s. Must be one of: count, name", sortBy)), nil
+	}
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	noteTags, err := collectNoteTags(backend)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to collect tags: %v", err)), nil
+	}
+
+	index := buildTagIndex(noteTags, nested)
+
+	var filtered []types.TagInfo
+	for _, info := range index {
+		if prefix == "" || strings.HasPrefix(strings.ToLower(info.Tag), prefix) {
+			filtered = append(filtered, info)
+		}
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		if sortBy == "count" && filtered[i].Count != filtered[j].Count {
+			return filtered[i].Count > filtered[j].Count
+		}
+		return strings.ToLower(filtered[i].Tag) < strings.ToLower(filtered[j].Tag)
+	})
+
+	totalTags := len(filtered)
+	if limit > 0 && len(filtered) > limit {
+		filtered = filtered[:limit]
+	}
+	if filtered == nil {
+		filtered = []types.TagInfo{}
+	}
+
+	structureData := map[string]interface{}{
+		"total_tags":  totalTags,
+		"total_notes": len(noteTags),
+		"sort":        sortBy,
+		"nested":      nested,
+		"tags":        filtered,
+	}
+	if prefix != "" {
+		structureData["prefix"] = prefix
+	}
+
+	jsonData, err := json.MarshalIndent(structureData, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// collectNoteTags returns the tags of every note keyed by note path. Backends
+// with JsonLogic support answer in a single query; others are read note by note.
+func collectNoteTags(backend client.VaultBackend) (map[string][]string, error) {
+	noteTags := make(map[string][]string)
+
+	if jsonSearch, ok := backend.(client.JSONSearchBackend); ok {
+		results, err := jsonSearch.SearchJSON(map[string]interface{}{"var": "tags"})
+		if err == nil {
+			for _, result := range results {
+				values, ok := result.Result.([]interface{})
+				if !ok {
+					continue
+				}
+				for _, value := range values {
+					if tag, ok := value.(string); ok {
+						noteTags[result.Filename] = append(noteTags[result.Filename], tag)
+					}
+				}
+			}
+			return noteTags, nil
+		}
+	}
+
+	paths, err := listVaultNotes(backend, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, note := range fetchNotes(backend, paths) {
+		noteTags[note.Path] = note.Tags
+	}
+
+	return noteTags, nil
+}
+
+// buildTagIndex aggregates note tags into TagInfo entries. When nested is
+// true a note tagged #a/b also counts towards #a.
+func buildTagIndex(noteTags map[string][]string, nested bool) []types.TagInfo {
+	type tagEntry struct {
+		tag   string
+		files map[string]bool
+	}
+	entries := make(map[string]*tagEntry)
+
+	add := func(tag, file string) {
+		key := strings.ToLower(tag)
+		entry, ok := entries[key]
+		if !ok {
+			entry = &tagEntry{tag: tag, files: make(map[string]bool)}
+			entries[key] = entry
+		}
+		entry.files[file] = true
+	}
+
+	for file, fileTags := range noteTags {
+		for _, tag := range fileTags {
+			tag = tags.Normalize(tag)
+			if tag == "" {
+				continue
+			}
+			add(tag, file)
+			if nested {
+				for _, parent := range tags.Parents(tag) {
+					add(parent, file)
+				}
+			}
+		}
+	}
+
+	index := make([]types.TagInfo, 0, len(entries))
+	for _, entry := range entries {
+		files := make([]string, 0, len(entry.files))
+		for file := range entry.files {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		index = append(index, types.TagInfo{
+			Tag:   entry.tag,
+			Count: len(files),
+			Files: files,
+		})
+	}
+
+	return index
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"mcp-obsidian/obsidian/dates"
+	"mcp-obsidian/obsidian/tags"
+	"mcp-obsidian/obsidian/tasks"
+	"mcp-obsidian/obsidian/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ListTasks lists checkbox tasks across the vault or a folder
+func ListTasks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	folder := strings.Trim(req.GetString("folder", ""), "/")
+	tag := strings.ToLower(tags.Normalize(req.GetString("tag", "")))
+	limit := req.GetInt("limit", 0)
+
+	statuses, err := parseTaskStatuses(req.GetString("status", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	dueFrom, dueTo, err := taskDueRange(req.GetString("due_from", ""), req.GetString("due_to", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	paths, err := listVaultNotes(backend, folder)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list notes: %v", err)), nil
+	}
+
+	found := []types.Task{}
+	byStatus := make(map[string]int)
+	for _, note := range fetchNotes(backend, paths) {
+		for _, task := range tasks.Parse(note.Path, note.Content) {
+			if len(statuses) > 0 && !statuses[task.Status] && !statuses[task.StatusName] {
+				continue
+			}
+			if (dueFrom != "" || dueTo != "") && (task.Due == "" || task.Due < dueFrom || (dueTo != "" && task.Due > dueTo)) {
+				continue
+			}
+			if tag != "" && !hasTaskTag(task, tag) {
+				continue
+			}
+			byStatus[task.StatusName]++
+			found = append(found, task)
+		}
+	}
+
+	total := len(found)
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+
+	result := map[string]interface{}{
+		"total_tasks": total,
+		"returned":    len(found),
+		"by_status":   byStatus,
+		"tasks":       found,
+	}
+	if folder != "" {
+		result["folder"] = folder
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// UpdateTask changes the status or dates of a task addressed by line or block ID
+func UpdateTask(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath, err := req.RequireString("filepath")
+	if err != nil {
+		return mcp.NewToolResultError("filepath parameter required"), nil
+	}
+	operation := req.GetString("operation", "toggle")
+	line := req.GetInt("line", 0)
+	blockID := req.GetString("block_id", "")
+	if line <= 0 && blockID == "" {
+		return mcp.NewToolResultError("line or block_id parameter required"), nil
+	}
+
+	backend, err := getBackend()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get vault backend: %v", err)), nil
+	}
+
+	content, err := backend.GetFileContents(filePath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get file contents for %s: %v", filePath, err)), nil
+	}
+
+	task, ok := tasks.Find(tasks.Parse(filePath, content), line, blockID)
+	if !ok {
+		if blockID != "" {
+			return mcp.NewToolResultError(fmt.Sprintf("no task with block ID %s in %s", blockID, filePath)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("no task at line %d in %s", line, filePath)), nil
+	}
+
+	lines := strings.Split(content, "\n")
+	text := strings.TrimSuffix(lines[task.Line-1], "\r")
+	lineEnd := strings.TrimPrefix(lines[task.Line-1], text)
+	updated, completedOn, err := editTask(req, task, operation, text)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	lines[task.Line-1] = updated + lineEnd
+
+	// Completing a recurring task inserts its next occurrence above it, as the Tasks plugin does
+	taskLine := task.Line
+	var nextLine int
+	if completedOn != "" && task.Recurrence != "" && task.StatusName != tasks.StatusDone {
+		next, err := tasks.NextOccurrence(text, task, completedOn)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to schedule the next occurrence: %v. Use operation set_status with status done to complete the task without recurring", err)), nil
+		}
+		lines = append(lines[:task.Line-1], append([]string{next + lineEnd}, lines[task.Line-1:]...)...)
+		nextLine = task.Line
+		taskLine++
+	}
+	newContent := strings.Join(lines, "\n")
+
+	if newContent != content {
+		if err := backend.PutContent(filePath, newContent); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to update task: %v", err)), nil
+		}
+	}
+
+	updatedTasks := tasks.Parse(filePath, newContent)
+	after, _ := tasks.Find(updatedTasks, taskLine, "")
+
+	result := map[string]interface{}{
+		"filepath":  filePath,
+		"operation": operation,
+		"changed":   newContent != content,
+		"before":    task,
+		"task":      after,
+	}
+	if nextLine > 0 {
+		next, _ := tasks.Find(updatedTasks, nextLine, "")
+		result["next_occurrence"] = next
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// editTask applies an update_task operation to the text of a task line. When
+// the task is completed it also returns the completion date.
+func editTask(req mcp.CallToolRequest, task types.Task, operation, text string) (string, string, error) {
+	if operation == "toggle" {
+		operation = "complete"
+		if task.StatusName == tasks.StatusDone {
+			operation = "reopen"
+		}
+	}
+
+	var status, completedOn string
+	var changes [][2]string // date field and new value; empty values remove the field
+	switch operation {
+	case "complete":
+		doneDate, err := resolveTaskDate(req.GetString("done_date", "today"))
+		if err != nil {
+			return "", "", fmt.Errorf("invalid done_date: %v", err)
+		}
+		status, completedOn = "x", doneDate
+		changes = [][2]string{{tasks.FieldCancelled, ""}, {tasks.FieldDone, doneDate}}
+	case "cancel":
+		cancelled, err := resolveTaskDate(req.GetString("done_date", "today"))
+		if err != nil {
+			return "", "", fmt.Errorf("invalid done_date: %v", err)
+		}
+		status = "-"
+		changes = [][2]string{{tasks.FieldDone, ""}, {tasks.FieldCancelled, cancelled}}
+	case "reopen":
+		status = " "
+		changes = [][2]string{{tasks.FieldDone, ""}, {tasks.FieldCancelled, ""}}
+	case "start":
+		status = "/"
+	case "set_status":
+		char, err := tasks.StatusChar(req.GetString("status", ""))
+		if err != nil {
+			return "", "", err
+		}
+		status = char
+	case "reschedule":
+		for _, field := range []string{tasks.FieldDue, tasks.FieldScheduled, tasks.FieldStart} {
+			value := strings.TrimSpace(req.GetString(field, ""))
+			switch strings.ToLower(value) {
+			case "":
+				continue
+			case "none", "clear":
+				changes = append(changes, [2]string{field, ""})
+			default:
+				date, err := resolveTaskDate(value)
+				if err != nil {
+					return "", "", fmt.Errorf("invalid %s date: %v", field, err)
+				}
+				changes = append(changes, [2]string{field, date})
+			}
+		}
+		if len(changes) == 0 {
+			return "", "", fmt.Errorf("reschedule requires due, scheduled or start")
+		}
+	default:
+		return "", "", fmt.Errorf("invalid operation: %s. Must be one of: toggle, complete, cancel, reopen, start, set_status, reschedule", operation)
+	}
+
+	var err error
+	if status != "" {
+		if text, err = tasks.SetStatus(text, status); err != nil {
+			return "", "", err
+		}
+	}
+	for _, change := range changes {
+		if text, err = tasks.SetDate(text, change[0], change[1]); err != nil {
+			return "", "", err
+		}
+	}
+	return text, completedOn, nil
+}
+
+// resolveTaskDate resolves a date expression such as "today" or "next friday" to YYYY-MM-DD
+func resolveTaskDate(expr string) (string, error) {
+	opts, err := dateOptions()
+	if err != nil {
+		return "", err
+	}
+	span, err := dates.Resolve(expr, time.Now(), opts)
+	if err != nil {
+		return "", err
+	}
+	return span.Date(), nil
+}
+
+// taskDueRange resolves the due_from and due_to filters to inclusive YYYY-MM-DD bounds
+func taskDueRange(fromExpr, toExpr string) (string, string, error) {
+	opts, err := dateOptions()
+	if err != nil {
+		return "", "", fmt.Errorf("invalid date configuration: %v", err)
+	}
+
+	now := time.Now()
+	var from, to string
+	if strings.TrimSpace(fromExpr) != "" {
+		span, err := dates.Resolve(fromExpr, now, opts)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid due_from date: %v", err)
+		}
+		from = span.Date()
+	}
+	if strings.TrimSpace(toExpr) != "" {
+		span, err := dates.Resolve(toExpr, now, opts)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid due_to date: %v", err)
+		}
+		to = span.LastDay().Format(dates.DateLayout)
+	}
+	if from != "" && to != "" && to < from {
+		return "", "", fmt.Errorf("due_to must not be before due_from")
+	}
+
+	return from, to, nil
+}
+
+// parseTaskStatuses parses a comma-separated list of status names or characters
+func parseTaskStatuses(value string) (map[string]bool, error) {
+	if strings.TrimSpace(value) == "" || strings.EqualFold(strings.TrimSpace(value), "all") {
+		return nil, nil
+	}
+
+	statuses := make(map[string]bool)
+	for _, status := range strings.Split(value, ",") {
+		name := strings.ToLower(strings.TrimSpace(status))
+		switch name {
+		case tasks.StatusTodo, tasks.StatusDone, tasks.StatusInProgress, tasks.StatusCancelled, tasks.StatusOther:
+			statuses[name] = true
+		case "open":
+			statuses[tasks.StatusTodo] = true
+			statuses[tasks.StatusInProgress] = true
+		case "":
+			// "[ ]" written as a bare space is trimmed away; treat it as todo
+			statuses[tasks.StatusTodo] = true
+		default:
+			if len([]rune(name)) != 1 {
+				return nil, fmt.Errorf("invalid status: %s. Use todo, done, in_progress, cancelled, other, open or a status character", status)
+			}
+			statuses[strings.TrimSpace(status)] = true
+		}
+	}
+	return statuses, nil
+}
+
+// hasTaskTag reports whether a task carries tag or one of its nested tags
+func hasTaskTag(task types.Task, tag string) bool {
+	for _, taskTag := range task.Tags {
+		taskTag = strings.ToLower(taskTag)
+		if taskTag == tag || strings.HasPrefix(taskTag, tag+"/") {
+			return true
+		}
+	}
+	return false
+}
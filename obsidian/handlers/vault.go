@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"mcp-obsidian/obsidian/client"
+	"mcp-obsidian/obsidian/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// noteFetchWorkers bounds the number of concurrent note requests
+const noteFetchWorkers = 8
+
+// walkVaultFiles recursively lists every file below dirPath ("" for the vault
+// root). The returned entries carry vault-relative paths.
+func walkVaultFiles(backend client.VaultBackend, dirPath string) ([]types.FileInfo, error) {
+	var files []types.FileInfo
+	var err error
+	if dirPath == "" {
+		files, err = backend.ListFilesInVault()
+	} else {
+		files, err = backend.ListFilesInDir(dirPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.FileInfo
+	for _, file := range files {
+		fullPath := joinVaultPath(dirPath, strings.TrimSuffix(file.Path, "/"))
+		if file.Type == "directory" {
+			children, err := walkVaultFiles(backend, fullPath)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, children...)
+			continue
+		}
+		file.Path = fullPath
+		result = append(result, file)
+	}
+
+	return result, nil
+}
+
+// listVaultNotes recursively lists every markdown note below dirPath
+func listVaultNotes(backend client.VaultBackend, dirPath string) ([]string, error) {
+	files, err := walkVaultFiles(backend, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	for _, file := range files {
+		if isMarkdownFile(file.Path) {
+			notes = append(notes, file.Path)
+		}
+	}
+	return notes, nil
+}
+
+// fetchNotes loads notes with bounded concurrency. Notes that fail to load are
+// skipped; results keep the order of paths.
+func fetchNotes(backend client.VaultBackend, paths []string) []*types.NoteJSON {
+	notes, _ := fetchNotesReportingFailures(backend, paths)
+	return notes
+}
+
+// fetchNotesReportingFailures loads notes like fetchNotes and also returns
+// the notes that failed to load, in the order of paths, with their errors
+func fetchNotesReportingFailures(backend client.VaultBackend, paths []string) ([]*types.NoteJSON, []map[string]string) {
+	notes := make([]*types.NoteJSON, len(paths))
+	errs := make([]error, len(paths))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, noteFetchWorkers)
+	for i, notePath := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, notePath string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			notes[i], errs[i] = backend.GetNote(notePath)
+		}(i, notePath)
+	}
+	wg.Wait()
+
+	var loaded []*types.NoteJSON
+	var failed []map[string]string
+	for i, note := range notes {
+		switch {
+		case errs[i] != nil:
+			failed = append(failed, map[string]string{"path": paths[i], "error": errs[i].Error()})
+		case note != nil:
+			loaded = append(loaded, note)
+		}
+	}
+	return loaded, failed
+}
+
+// joinVaultPath joins vault-relative path segments using forward slashes
+func joinVaultPath(dirPath, name string) string {
+	if dirPath == "" {
+		return name
+	}
+	if strings.HasPrefix(name, dirPath+"/") {
+		// Some API versions already return paths relative to the vault root
+		return name
+	}
+	return path.Join(dirPath, name)
+}
+
+// isMarkdownFile reports whether a path is a markdown note
+func isMarkdownFile(filePath string) bool {
+	return strings.EqualFold(path.Ext(filePath), ".md")
+}
+
+// jsonResult marshals a tool response as indented JSON
+func jsonResult(data map[string]interface{}) (*mcp.CallToolResult, error) {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
@@ -0,0 +1,147 @@
+package links
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"strings"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// blockIDAlphabet is the character set of generated block IDs, as Obsidian uses
+const blockIDAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// blockIDMarker matches a ^block-id at the end of a line
+var blockIDMarker = regexp.MustCompile(`(^|\s)\^([A-Za-z0-9-]+)\s*$`)
+
+// RewritesForCopies works out how links in copied notes must change. copies
+// maps the vault path of each copied file to the path of its copy, and
+// blockIDs maps copied notes to their renamed block IDs (see NewBlockIDs).
+// The result is keyed by the path of the original note.
+//
+// With relinkPaths, links written as paths, relative or from the vault root, are pointed at
+// the copy when their target was copied too and recomputed from the new
+// location otherwise. Bare note names are left to Obsidian's resolution.
+// Links to renamed blocks follow the new IDs.
+func (g *Graph) RewritesForCopies(copies map[string]string, blockIDs map[string]map[string]string, relinkPaths bool) map[string][]types.LinkRewrite {
+	after := append([]string(nil), g.files...)
+	for _, to := range copies {
+		after = append(after, to)
+	}
+	resolver := NewResolver(after)
+
+	rewrites := make(map[string][]types.LinkRewrite)
+	for _, note := range g.notes {
+		newSource, ok := copies[note]
+		if !ok {
+			continue
+		}
+
+		for _, link := range g.outgoing[note] {
+			if link.Resolved == "" {
+				continue
+			}
+			newTarget := link.Resolved
+			if to, ok := copies[link.Resolved]; ok {
+				newTarget = to
+			}
+
+			text := link.Raw
+			relocated := link
+			relocated.Source = newSource
+			bareName := link.Kind == KindWikilink && !strings.Contains(link.Target, "/")
+			if relinkPaths && link.Target != "" && !bareName && resolver.Resolve(relocated) != newTarget {
+				if rewritten := g.relink(resolver, link, newSource, newTarget); rewritten != "" {
+					text = rewritten
+				}
+			}
+
+			if id, ok := blockIDs[link.Resolved][strings.ToLower(link.Block)]; ok && link.Block != "" {
+				text = strings.Replace(text, "^"+link.Block, "^"+id, 1)
+			}
+
+			if text != link.Raw {
+				rewrites[note] = append(rewrites[note], types.LinkRewrite{Line: link.Line, From: link.Raw, To: text})
+			}
+		}
+	}
+
+	return rewrites
+}
+
+// NewBlockIDs generates fresh block IDs for every block in the given notes.
+// The result maps each note to its lower-cased old IDs and their replacements,
+// which never clash with an ID already in the same note.
+func (g *Graph) NewBlockIDs(notes []string) map[string]map[string]string {
+	ids := make(map[string]map[string]string)
+	for _, note := range notes {
+		if len(g.blocks[note]) == 0 {
+			continue
+		}
+
+		renamed := make(map[string]string)
+		taken := make(map[string]bool)
+		for id := range g.blocks[note] {
+			taken[id] = true
+		}
+		for id := range g.blocks[note] {
+			fresh := newBlockID()
+			for taken[fresh] {
+				fresh = newBlockID()
+			}
+			taken[fresh] = true
+			renamed[id] = fresh
+		}
+		ids[note] = renamed
+	}
+	return ids
+}
+
+// ReplaceBlockIDs renames the ^block-id markers at the end of lines. ids maps
+// lower-cased old IDs to new ones; fenced code is left untouched. It returns
+// the updated content and the number of markers replaced.
+func ReplaceBlockIDs(content string, ids map[string]string) (string, int) {
+	if len(ids) == 0 {
+		return content, 0
+	}
+
+	lines := strings.Split(content, "\n")
+
+	replaced := 0
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		match := blockIDMarker.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		id, ok := ids[strings.ToLower(line[match[4]:match[5]])]
+		if !ok {
+			continue
+		}
+		lines[i] = line[:match[4]] + id + line[match[5]:]
+		replaced++
+	}
+
+	return strings.Join(lines, "\n"), replaced
+}
+
+// newBlockID returns a random six-character block ID
+func newBlockID() string {
+	id := make([]byte, 6)
+	for i := range id {
+		id[i] = blockIDAlphabet[rand.IntN(len(blockIDAlphabet))]
+	}
+	return string(id)
+}
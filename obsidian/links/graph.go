@@ -0,0 +1,168 @@
+package links
+
+import (
+	"sort"
+	"strings"
+
+	"mcp-obsidian/obsidian/markdown"
+	"mcp-obsidian/obsidian/types"
+)
+
+// Neighbor directions
+const (
+	DirectionBoth     = "both"
+	DirectionOutgoing = "outgoing"
+	DirectionIncoming = "incoming"
+)
+
+// Graph is the resolved link graph of a vault
+type Graph struct {
+	Resolver *Resolver
+
+	files    []string
+	notes    []string
+	outgoing map[string][]types.NoteLink
+	incoming map[string][]types.NoteLink
+	headings map[string]map[string]bool // note -> normalised heading titles
+	blocks   map[string]map[string]bool // note -> block IDs
+}
+
+// Build parses every note and resolves its links. files lists every file in
+// the vault, attachments included; notes maps note paths to their content.
+func Build(files []string, notes map[string]string) *Graph {
+	g := &Graph{
+		Resolver: NewResolver(files),
+		files:    append([]string(nil), files...),
+		outgoing: make(map[string][]types.NoteLink),
+		incoming: make(map[string][]types.NoteLink),
+		headings: make(map[string]map[string]bool),
+		blocks:   make(map[string]map[string]bool),
+	}
+	sort.Strings(g.files)
+
+	for note := range notes {
+		g.notes = append(g.notes, note)
+	}
+	sort.Strings(g.notes)
+
+	for _, note := range g.notes {
+		elements := markdown.Parse(notes[note])
+
+		headings := make(map[string]bool)
+		blocks := make(map[string]bool)
+		markdown.Walk(elements, func(element types.MarkdownElement) {
+			if element.Type == markdown.TypeHeading {
+				headings[normalizeHeading(element.Title)] = true
+			}
+			if id := element.Attributes["block_id"]; id != "" {
+				blocks[strings.ToLower(id)] = true
+			}
+		})
+		g.headings[note] = headings
+		g.blocks[note] = blocks
+
+		links := extractElements(note, elements)
+		for i := range links {
+			links[i].Resolved = g.Resolver.Resolve(links[i])
+			if resolved := links[i].Resolved; resolved != "" && resolved != note {
+				g.incoming[resolved] = append(g.incoming[resolved], links[i])
+			}
+		}
+		g.outgoing[note] = links
+	}
+
+	return g
+}
+
+// Files returns every file in the vault, sorted
+func (g *Graph) Files() []string {
+	return g.files
+}
+
+// Notes returns every parsed note, sorted
+func (g *Graph) Notes() []string {
+	return g.notes
+}
+
+// Outgoing returns the links of a note in document order
+func (g *Graph) Outgoing(note string) []types.NoteLink {
+	return g.outgoing[note]
+}
+
+// Backlinks returns the links from other notes to a file, ordered by source and line
+func (g *Graph) Backlinks(file string) []types.NoteLink {
+	return g.incoming[file]
+}
+
+// HasHeading reports whether a note has the headings of a subpath such as "Parent#Child"
+func (g *Graph) HasHeading(note, subpath string) bool {
+	headings := g.headings[note]
+	for _, part := range strings.Split(subpath, "#") {
+		if part = normalizeHeading(part); part != "" && !headings[part] {
+			return false
+		}
+	}
+	return true
+}
+
+// HasBlock reports whether a note carries a ^block ID
+func (g *Graph) HasBlock(note, id string) bool {
+	return g.blocks[note][strings.ToLower(id)]
+}
+
+// Neighbors walks the graph breadth first from a file up to depth links away,
+// following outgoing links, incoming links or both. The starting file is not
+// included; neighbors are ordered by distance and path.
+func (g *Graph) Neighbors(start string, depth int, direction string) []types.NoteNeighbor {
+	seen := map[string]bool{start: true}
+	var neighbors []types.NoteNeighbor
+
+	frontier := []string{start}
+	for distance := 1; distance <= depth && len(frontier) > 0; distance++ {
+		var next []types.NoteNeighbor
+		visit := func(file, via, edge string) {
+			if seen[file] {
+				return
+			}
+			seen[file] = true
+			next = append(next, types.NoteNeighbor{Path: file, Distance: distance, Via: via, Direction: edge})
+		}
+
+		for _, file := range frontier {
+			if direction != DirectionIncoming {
+				for _, link := range g.outgoing[file] {
+					if link.Resolved != "" {
+						visit(link.Resolved, file, DirectionOutgoing)
+					}
+				}
+			}
+			if direction != DirectionOutgoing {
+				for _, link := range g.incoming[file] {
+					visit(link.Source, file, DirectionIncoming)
+				}
+			}
+		}
+
+		sort.Slice(next, func(i, j int) bool { return next[i].Path < next[j].Path })
+		frontier = frontier[:0]
+		for _, neighbor := range next {
+			frontier = append(frontier, neighbor.Path)
+		}
+		neighbors = append(neighbors, next...)
+	}
+
+	return neighbors
+}
+
+// normalizeHeading folds a heading the way Obsidian matches link subpaths:
+// case-insensitive, with characters that cannot appear in links treated as spaces
+func normalizeHeading(heading string) string {
+	heading = strings.Map(func(r rune) rune {
+		switch r {
+		case '#', '^', '|', ':', '[', ']', '%':
+			return ' '
+		}
+		return r
+	}, heading)
+	return strings.ToLower(strings.Join(strings.Fields(heading), " "))
+}
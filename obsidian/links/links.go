@@ -0,0 +1,231 @@
+// Package links extracts links from notes, resolves them to vault paths the
+// way Obsidian does and builds a vault-wide link graph.
+package links
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"mcp-obsidian/obsidian/markdown"
+	"mcp-obsidian/obsidian/types"
+)
+
+// Link kinds
+const (
+	KindWikilink = "wikilink"
+	KindMarkdown = "markdown"
+)
+
+// schemePattern matches external link destinations such as https: or mailto:
+var schemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+
+// Extract returns the internal links of a note in document order: wikilinks
+// and embeds, including those in frontmatter properties, and markdown links
+// and images with vault-relative destinations. Links in code and comments are
+// ignored. Links are not resolved.
+func Extract(source, content string) []types.NoteLink {
+	return extractElements(source, markdown.Parse(content))
+}
+
+func extractElements(source string, elements []types.MarkdownElement) []types.NoteLink {
+	var links []types.NoteLink
+
+	var visit func(element types.MarkdownElement)
+	visit = func(element types.MarkdownElement) {
+		switch element.Type {
+		case markdown.TypeWikilink, markdown.TypeEmbed:
+			links = append(links, types.NoteLink{
+				Source:  source,
+				Line:    element.Line,
+				Raw:     strings.TrimSpace(element.Content),
+				Kind:    KindWikilink,
+				Embed:   element.Type == markdown.TypeEmbed,
+				Target:  element.Attributes["target"],
+				Heading: element.Attributes["heading"],
+				Block:   element.Attributes["block"],
+				Alias:   element.Attributes["alias"],
+			})
+		case markdown.TypeLink, markdown.TypeImage:
+			destination := element.Attributes["url"]
+			if element.Type == markdown.TypeImage {
+				destination = element.Attributes["src"]
+			}
+			if link, ok := markdownLink(source, destination); ok {
+				link.Line = element.Line
+				link.Raw = strings.TrimSpace(element.Content)
+				link.Embed = element.Type == markdown.TypeImage
+				link.Alias = element.Title
+				links = append(links, link)
+			}
+		case markdown.TypeFrontmatter:
+			// Properties may hold wikilinks such as related: "[[Note]]"
+			for _, child := range markdown.Inline(element.Content, element.Line+1) {
+				if child.Type == markdown.TypeWikilink || child.Type == markdown.TypeEmbed {
+					visit(child)
+				}
+			}
+		}
+
+		for _, child := range element.Children {
+			visit(child)
+		}
+	}
+
+	for _, element := range elements {
+		visit(element)
+	}
+
+	return links
+}
+
+// markdownLink converts a markdown link destination into a link, rejecting
+// external URLs
+func markdownLink(source, destination string) (types.NoteLink, bool) {
+	if destination == "" || schemePattern.MatchString(destination) || strings.HasPrefix(destination, "//") {
+		return types.NoteLink{}, false
+	}
+
+	target, fragment, _ := strings.Cut(destination, "#")
+	if decoded, err := url.PathUnescape(target); err == nil {
+		target = decoded
+	}
+	if decoded, err := url.PathUnescape(fragment); err == nil {
+		fragment = decoded
+	}
+
+	link := types.NoteLink{Source: source, Kind: KindMarkdown, Target: target}
+	if strings.HasPrefix(fragment, "^") {
+		link.Block = strings.TrimPrefix(fragment, "^")
+	} else {
+		link.Heading = fragment
+	}
+	return link, true
+}
+
+// Resolver resolves link targets to vault paths
+type Resolver struct {
+	exists map[string]bool
+	files  map[string]string   // lower-case path -> path
+	byName map[string][]string // lower-case file name, and note name without .md -> paths
+}
+
+// NewResolver indexes the files of a vault by path and name
+func NewResolver(files []string) *Resolver {
+	r := &Resolver{
+		exists: make(map[string]bool, len(files)),
+		files:  make(map[string]string, len(files)),
+		byName: make(map[string][]string),
+	}
+
+	for _, file := range files {
+		r.exists[file] = true
+		lower := strings.ToLower(file)
+		r.files[lower] = file
+
+		name := path.Base(lower)
+		r.byName[name] = append(r.byName[name], file)
+		if strings.HasSuffix(name, ".md") {
+			base := strings.TrimSuffix(name, ".md")
+			r.byName[base] = append(r.byName[base], file)
+		}
+	}
+
+	return r
+}
+
+// Resolve returns the vault path a link points to, or "" when it is unresolved.
+// Links without a target point at their own note.
+func (r *Resolver) Resolve(link types.NoteLink) string {
+	if link.Target == "" {
+		return link.Source
+	}
+
+	if link.Kind == KindMarkdown {
+		target := link.Target
+		if !strings.HasPrefix(target, "/") {
+			if resolved := r.exact(path.Join(path.Dir(link.Source), target)); resolved != "" {
+				return resolved
+			}
+		}
+		if resolved := r.exact(strings.TrimPrefix(target, "/")); resolved != "" {
+			return resolved
+		}
+	}
+
+	return r.resolveName(link.Source, link.Target)
+}
+
+// Lookup finds a file from a user-supplied path or note name
+func (r *Resolver) Lookup(name string) (string, bool) {
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	if resolved := r.exact(name); resolved != "" {
+		return resolved, true
+	}
+	if resolved := r.resolveName("", name); resolved != "" {
+		return resolved, true
+	}
+	return "", false
+}
+
+// exact matches a vault path with or without the .md extension, preferring
+// a case-sensitive match
+func (r *Resolver) exact(filePath string) string {
+	filePath = path.Clean(filePath)
+	for _, candidate := range []string{filePath, filePath + ".md"} {
+		if r.exists[candidate] {
+			return candidate
+		}
+	}
+
+	lower := strings.ToLower(filePath)
+	if file, ok := r.files[lower]; ok {
+		return file
+	}
+	if file, ok := r.files[lower+".md"]; ok {
+		return file
+	}
+	return ""
+}
+
+// resolveName resolves a wikilink target: a full path, a path relative to the
+// source note, or a (partial) path suffix or file name. When several files
+// share a name, the one next to the source note wins, then the shortest path.
+func (r *Resolver) resolveName(source, target string) string {
+	target = strings.TrimPrefix(strings.TrimSpace(target), "/")
+	if resolved := r.exact(target); resolved != "" {
+		return resolved
+	}
+	if source != "" && (strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../")) {
+		if resolved := r.exact(path.Join(path.Dir(source), target)); resolved != "" {
+			return resolved
+		}
+	}
+
+	lower := strings.ToLower(target)
+	var candidates []string
+	for _, file := range r.byName[path.Base(lower)] {
+		fileLower := strings.ToLower(file)
+		if !strings.Contains(lower, "/") || strings.HasSuffix(fileLower, "/"+lower) || strings.HasSuffix(fileLower, "/"+lower+".md") {
+			candidates = append(candidates, file)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sourceDir := path.Dir(source)
+	sort.Slice(candidates, func(i, j int) bool {
+		iLocal, jLocal := path.Dir(candidates[i]) == sourceDir, path.Dir(candidates[j]) == sourceDir
+		if iLocal != jLocal {
+			return iLocal
+		}
+		if len(candidates[i]) != len(candidates[j]) {
+			return len(candidates[i]) < len(candidates[j])
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0]
+}
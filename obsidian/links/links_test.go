@@ -0,0 +1,227 @@
+package links
+
+import (
+	"reflect"
+	"testing"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+func TestResolve(t *testing.T) {
+	resolver := NewResolver([]string{
+		"Note.md",
+		"a/Note.md",
+		"a/b/Note.md",
+		"z/Other.md",
+		"y/Other.md",
+		"img/pic.png",
+		"docs/spec.pdf",
+		"Mixed Case.md",
+	})
+
+	tests := []struct {
+		name string
+		link types.NoteLink
+		want string
+	}{
+		{"bare name prefers the shortest path", types.NoteLink{Source: "x/src.md", Kind: KindWikilink, Target: "Note"}, "Note.md"},
+		{"bare name prefers the linking note's folder", types.NoteLink{Source: "z/src.md", Kind: KindWikilink, Target: "Other"}, "z/Other.md"},
+		{"vault path wins over the linking note's folder", types.NoteLink{Source: "a/b/src.md", Kind: KindWikilink, Target: "Note"}, "Note.md"},
+		{"equal lengths tie break by path", types.NoteLink{Source: "src.md", Kind: KindWikilink, Target: "Other"}, "y/Other.md"},
+		{"partial path", types.NoteLink{Source: "src.md", Kind: KindWikilink, Target: "b/Note"}, "a/b/Note.md"},
+		{"full path with extension", types.NoteLink{Source: "src.md", Kind: KindWikilink, Target: "a/Note.md"}, "a/Note.md"},
+		{"relative wikilink", types.NoteLink{Source: "a/b/src.md", Kind: KindWikilink, Target: "../Note"}, "a/Note.md"},
+		{"case-insensitive name", types.NoteLink{Source: "src.md", Kind: KindWikilink, Target: "mixed case"}, "Mixed Case.md"},
+		{"attachment by name", types.NoteLink{Source: "src.md", Kind: KindWikilink, Target: "pic.png"}, "img/pic.png"},
+		{"attachment needs its extension", types.NoteLink{Source: "src.md", Kind: KindWikilink, Target: "pic"}, ""},
+		{"markdown link relative to the note", types.NoteLink{Source: "a/src.md", Kind: KindMarkdown, Target: "b/Note.md"}, "a/b/Note.md"},
+		{"markdown link from the vault root", types.NoteLink{Source: "a/src.md", Kind: KindMarkdown, Target: "/docs/spec.pdf"}, "docs/spec.pdf"},
+		{"empty target is the note itself", types.NoteLink{Source: "a/src.md", Kind: KindWikilink}, "a/src.md"},
+		{"unresolved", types.NoteLink{Source: "src.md", Kind: KindWikilink, Target: "Missing"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := resolver.Resolve(tt.link); got != tt.want {
+			t.Errorf("%s: Resolve(%q) = %q, want %q", tt.name, tt.link.Target, got, tt.want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	content := "---\nrelated: \"[[Fm]]\"\n---\n[[A#H|alias]] ![[pic.png]] [x](B.md#^blk) ![i](img/pic.png)\n[ext](https://example.com) `[[code]]`\n```\n[[fenced]]\n```\n%%[[comment]]%%\n"
+
+	var got []string
+	for _, link := range Extract("src.md", content) {
+		got = append(got, link.Raw)
+	}
+	want := []string{"[[Fm]]", "[[A#H|alias]]", "![[pic.png]]", "[x](B.md#^blk)", "![i](img/pic.png)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Extract = %q, want %q", got, want)
+	}
+
+	links := Extract("src.md", content)
+	if link := links[1]; link.Target != "A" || link.Heading != "H" || link.Alias != "alias" || link.Embed {
+		t.Errorf("wikilink = %+v, want target A, heading H and alias", link)
+	}
+	if link := links[2]; !link.Embed || link.Target != "pic.png" {
+		t.Errorf("embed = %+v, want an embed of pic.png", link)
+	}
+	if link := links[3]; link.Kind != KindMarkdown || link.Target != "B.md" || link.Block != "blk" {
+		t.Errorf("markdown link = %+v, want B.md with block blk", link)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	g := Build(
+		[]string{"a.md", "b.md", "c.md", "img/pic.png", "docs/spec.pdf"},
+		map[string]string{
+			"a.md": "[[b]] ![[pic.png]]\n[spec](docs/spec.pdf) [[a#Self]]\n",
+			"b.md": "[[c]]\n",
+			"c.md": "[[missing]]\n",
+		},
+	)
+
+	var backlinks []string
+	for _, link := range g.Backlinks("b.md") {
+		backlinks = append(backlinks, link.Source)
+	}
+	if !reflect.DeepEqual(backlinks, []string{"a.md"}) {
+		t.Errorf("Backlinks(b.md) = %q, want [a.md]", backlinks)
+	}
+	if links := g.Backlinks("a.md"); len(links) != 0 {
+		t.Errorf("Backlinks(a.md) = %+v, want links to itself left out", links)
+	}
+	if links := g.Backlinks("img/pic.png"); len(links) != 1 || !links[0].Embed {
+		t.Errorf("Backlinks(img/pic.png) = %+v, want the embed from a.md", links)
+	}
+	if links := g.Backlinks("docs/spec.pdf"); len(links) != 1 || links[0].Kind != KindMarkdown {
+		t.Errorf("Backlinks(docs/spec.pdf) = %+v, want the markdown link from a.md", links)
+	}
+
+	neighbors := g.Neighbors("b.md", 2, DirectionBoth)
+	want := []types.NoteNeighbor{
+		{Path: "a.md", Distance: 1, Via: "b.md", Direction: DirectionIncoming},
+		{Path: "c.md", Distance: 1, Via: "b.md", Direction: DirectionOutgoing},
+		{Path: "docs/spec.pdf", Distance: 2, Via: "a.md", Direction: DirectionOutgoing},
+		{Path: "img/pic.png", Distance: 2, Via: "a.md", Direction: DirectionOutgoing},
+	}
+	if !reflect.DeepEqual(neighbors, want) {
+		t.Errorf("Neighbors(b.md) = %+v, want %+v", neighbors, want)
+	}
+
+	if outgoing := g.Neighbors("b.md", 1, DirectionOutgoing); len(outgoing) != 1 || outgoing[0].Path != "c.md" {
+		t.Errorf("Neighbors(b.md, outgoing) = %+v, want only c.md", outgoing)
+	}
+}
+
+func TestLint(t *testing.T) {
+	g := Build(
+		[]string{"index.md", "a.md", "orphan.md", "sub/b.md", "img/used.png", "img/unused.png", "board.canvas"},
+		map[string]string{
+			"index.md":  "[[a#Intro]] [[a#Nope]] [[a#^blk]] [[a#^gone]]\n[[missing]] ![[used.png]] [[sub/b]]\n",
+			"a.md":      "# Intro\ntext ^blk\n\n[[index]]\n",
+			"orphan.md": "[[sub/b#Parent#Child]]\n",
+			"sub/b.md":  "# Parent\n## Child\n[[used.png#Heading]]\n",
+		},
+	)
+
+	want := []types.LinkFinding{
+		{Kind: CheckUnusedAttachment, Path: "img/unused.png", Line: 1, Message: "no note links to or embeds this attachment"},
+		{Kind: CheckMissingHeading, Path: "index.md", Line: 1, Link: "[[a#Nope]]", Message: `heading "Nope" not found in a.md`},
+		{Kind: CheckMissingBlock, Path: "index.md", Line: 1, Link: "[[a#^gone]]", Message: "block ^gone not found in a.md"},
+		{Kind: CheckUnresolved, Path: "index.md", Line: 2, Link: "[[missing]]", Message: `link target "missing" does not exist`},
+		{Kind: CheckOrphanNote, Path: "orphan.md", Line: 1, Message: "no other note links to this note"},
+	}
+	if got := g.Lint(LintOptions{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint = %+v, want %+v", got, want)
+	}
+
+	got := g.Lint(LintOptions{Checks: []string{CheckOrphanNote, CheckUnusedAttachment}, Folder: "img"})
+	if len(got) != 1 || got[0].Path != "img/unused.png" {
+		t.Errorf("Lint(img, orphans and attachments) = %+v, want only img/unused.png", got)
+	}
+
+	counts := CountFindings(g.Lint(LintOptions{}))
+	if counts[CheckMissingHeading] != 1 || counts[CheckOrphanNote] != 1 || counts[CheckUnresolved] != 1 {
+		t.Errorf("CountFindings = %v, want one finding of each kind", counts)
+	}
+}
+
+func TestRewritesForCopies(t *testing.T) {
+	g := Build(
+		[]string{"src/a.md", "src/b.md", "other.md"},
+		map[string]string{
+			"src/a.md": "[[b]] [x](b.md) [y](../other.md) [[src/b#^blk]] [z](b.md#^BLK)\n",
+			"src/b.md": "para ^blk\n",
+			"other.md": "[[src/b#^blk]]\n",
+		},
+	)
+	copies := map[string]string{"src/a.md": "dst/a.md", "src/b.md": "dst/b.md"}
+	blockIDs := map[string]map[string]string{"src/b.md": {"blk": "fresh1"}}
+
+	tests := []struct {
+		name        string
+		relinkPaths bool
+		want        map[string][]types.LinkRewrite
+	}{
+		{
+			name:        "paths follow the copies",
+			relinkPaths: true,
+			want: map[string][]types.LinkRewrite{
+				"src/a.md": {
+					{Line: 1, From: "[[src/b#^blk]]", To: "[[dst/b#^fresh1]]"},
+					{Line: 1, From: "[z](b.md#^BLK)", To: "[z](b.md#^fresh1)"},
+				},
+			},
+		},
+		{
+			name: "only block IDs change",
+			want: map[string][]types.LinkRewrite{
+				"src/a.md": {
+					{Line: 1, From: "[[src/b#^blk]]", To: "[[src/b#^fresh1]]"},
+					{Line: 1, From: "[z](b.md#^BLK)", To: "[z](b.md#^fresh1)"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.RewritesForCopies(copies, blockIDs, tt.relinkPaths)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RewritesForCopies = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBlockIDs(t *testing.T) {
+	g := Build([]string{"a.md", "b.md"}, map[string]string{
+		"a.md": "one ^x\n\ntwo ^Y\n",
+		"b.md": "no blocks\n",
+	})
+
+	ids := g.NewBlockIDs([]string{"a.md", "b.md"})
+	if _, ok := ids["b.md"]; ok {
+		t.Errorf("NewBlockIDs gave b.md IDs although it has no blocks")
+	}
+	renamed := ids["a.md"]
+	if len(renamed) != 2 || renamed["x"] == "" || renamed["y"] == "" || renamed["x"] == renamed["y"] {
+		t.Fatalf("NewBlockIDs(a.md) = %v, want two distinct IDs for x and y", renamed)
+	}
+	for _, id := range renamed {
+		if len(id) != 6 || id == "x" || id == "y" {
+			t.Errorf("NewBlockIDs produced %q, want a fresh six-character ID", id)
+		}
+	}
+}
+
+func TestReplaceBlockIDs(t *testing.T) {
+	content := "para ^abc\n```\ncode ^abc\n```\n- item ^ABC \nnot^abc\n^other\n"
+	got, replaced := ReplaceBlockIDs(content, map[string]string{"abc": "new123"})
+
+	want := "para ^new123\n```\ncode ^abc\n```\n- item ^new123 \nnot^abc\n^other\n"
+	if got != want || replaced != 2 {
+		t.Errorf("ReplaceBlockIDs = %q, %d, want %q, 2", got, replaced, want)
+	}
+}
@@ -0,0 +1,109 @@
+package links
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// Lint checks
+const (
+	CheckUnresolved       = "unresolved_link"
+	CheckMissingHeading   = "missing_heading"
+	CheckMissingBlock     = "missing_block"
+	CheckOrphanNote       = "orphan_note"
+	CheckUnusedAttachment = "unused_attachment"
+)
+
+// LintChecks lists every lint check in report order
+var LintChecks = []string{CheckUnresolved, CheckMissingHeading, CheckMissingBlock, CheckOrphanNote, CheckUnusedAttachment}
+
+// LintOptions selects what Lint reports
+type LintOptions struct {
+	Checks []string // checks to run; empty runs all of them
+	Folder string   // only report files below this folder; empty for the whole vault
+}
+
+// Lint reports unresolved links, links to missing headings or block IDs,
+// notes no other note links to and attachments nothing references. Findings
+// are ordered by path and line.
+func (g *Graph) Lint(options LintOptions) []types.LinkFinding {
+	enabled := make(map[string]bool)
+	for _, check := range options.Checks {
+		enabled[check] = true
+	}
+	if len(enabled) == 0 {
+		for _, check := range LintChecks {
+			enabled[check] = true
+		}
+	}
+
+	folder := strings.Trim(options.Folder, "/")
+	inScope := func(file string) bool {
+		return folder == "" || strings.HasPrefix(strings.ToLower(file), strings.ToLower(folder)+"/")
+	}
+
+	var findings []types.LinkFinding
+	add := func(check, file string, line int, link, message string) {
+		if enabled[check] && inScope(file) {
+			findings = append(findings, types.LinkFinding{Kind: check, Path: file, Line: line, Link: link, Message: message})
+		}
+	}
+
+	for _, note := range g.notes {
+		for _, link := range g.outgoing[note] {
+			switch {
+			case link.Resolved == "":
+				add(CheckUnresolved, note, link.Line, link.Raw, fmt.Sprintf("link target %q does not exist", link.Target))
+			case g.headings[link.Resolved] == nil:
+				// Headings and blocks can only be checked in notes
+			case link.Heading != "" && !g.HasHeading(link.Resolved, link.Heading):
+				add(CheckMissingHeading, note, link.Line, link.Raw, fmt.Sprintf("heading %q not found in %s", link.Heading, link.Resolved))
+			case link.Block != "" && !g.HasBlock(link.Resolved, link.Block):
+				add(CheckMissingBlock, note, link.Line, link.Raw, fmt.Sprintf("block ^%s not found in %s", link.Block, link.Resolved))
+			}
+		}
+
+		if len(g.incoming[note]) == 0 {
+			add(CheckOrphanNote, note, 1, "", "no other note links to this note")
+		}
+	}
+
+	for _, file := range g.files {
+		if isNoteFile(file) || len(g.incoming[file]) > 0 {
+			continue
+		}
+		add(CheckUnusedAttachment, file, 1, "", "no note links to or embeds this attachment")
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+
+	return findings
+}
+
+// CountFindings counts findings per check
+func CountFindings(findings []types.LinkFinding) map[string]int {
+	counts := make(map[string]int)
+	for _, finding := range findings {
+		counts[finding.Kind]++
+	}
+	return counts
+}
+
+// isNoteFile reports whether a file is a note rather than an attachment.
+// Canvas files are treated as notes since their links are not parsed.
+func isNoteFile(file string) bool {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".md", ".canvas":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package links
+
+import (
+	"path"
+	"strings"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// RewritesForMoves works out how links across the vault must change when
+// files move. moves maps old vault paths to new ones. The result is keyed by
+// the path of each affected note before the move.
+//
+// Every link keeps the style it was written in: a bare note name stays the
+// shortest name that still resolves, a vault path stays a vault path, and
+// relative links are recomputed from the (possibly moved) linking note.
+// Bare note names that still resolve to the right file are left alone; links
+// written as paths are updated whenever their source or target moves.
+func (g *Graph) RewritesForMoves(moves map[string]string) map[string][]types.LinkRewrite {
+	moved := func(file string) string {
+		if to, ok := moves[file]; ok {
+			return to
+		}
+		return file
+	}
+
+	var after []string
+	for _, file := range g.files {
+		after = append(after, moved(file))
+	}
+	resolver := NewResolver(after)
+
+	rewrites := make(map[string][]types.LinkRewrite)
+	for _, note := range g.notes {
+		newSource := moved(note)
+		for _, link := range g.outgoing[note] {
+			if link.Resolved == "" || link.Target == "" {
+				continue
+			}
+			newTarget := moved(link.Resolved)
+
+			relocated := link
+			relocated.Source = newSource
+			unchanged := newSource == note && newTarget == link.Resolved
+			bareName := link.Kind == KindWikilink && !strings.Contains(link.Target, "/")
+			if (unchanged || bareName) && resolver.Resolve(relocated) == newTarget {
+				continue
+			}
+
+			rewritten := g.relink(resolver, link, newSource, newTarget)
+			if rewritten != "" && rewritten != link.Raw {
+				rewrites[note] = append(rewrites[note], types.LinkRewrite{Line: link.Line, From: link.Raw, To: rewritten})
+			}
+		}
+	}
+
+	return rewrites
+}
+
+// ApplyRewrites replaces link text in note content. Rewrites must be in
+// document order; it returns the updated content and how many were applied.
+func ApplyRewrites(content string, rewrites []types.LinkRewrite) (string, int) {
+	lines := strings.Split(content, "\n")
+	cursor := make(map[int]int)
+
+	applied := 0
+	for _, rewrite := range rewrites {
+		index := rewrite.Line - 1
+		if index < 0 || index >= len(lines) {
+			continue
+		}
+
+		line := lines[index]
+		offset := strings.Index(line[cursor[index]:], rewrite.From)
+		if offset < 0 {
+			continue
+		}
+		offset += cursor[index]
+
+		lines[index] = line[:offset] + rewrite.To + line[offset+len(rewrite.From):]
+		cursor[index] = offset + len(rewrite.To)
+		applied++
+	}
+
+	return strings.Join(lines, "\n"), applied
+}
+
+// relink returns the text of link pointing from source to target, in the
+// style of the original. after resolves links once the change is made.
+func (g *Graph) relink(after *Resolver, link types.NoteLink, source, target string) string {
+	if link.Kind == KindMarkdown {
+		return rewriteMarkdownLink(g.Resolver, link, source, target)
+	}
+	return rewriteWikilink(after, link, source, target)
+}
+
+// rewriteWikilink returns the link text pointing at newTarget in the style of
+// the original link
+func rewriteWikilink(resolver *Resolver, link types.NoteLink, source, newTarget string) string {
+	keepExtension := !strings.EqualFold(path.Ext(newTarget), ".md") || strings.HasSuffix(strings.ToLower(link.Target), ".md")
+	display := newTarget
+	if !keepExtension {
+		display = strings.TrimSuffix(newTarget, path.Ext(newTarget))
+	}
+
+	var target string
+	switch {
+	case strings.HasPrefix(link.Target, "./") || strings.HasPrefix(link.Target, "../"):
+		target = relativePath(path.Dir(source), display)
+		if !strings.HasPrefix(target, "../") {
+			target = "./" + target
+		}
+	case strings.Contains(link.Target, "/"):
+		target = display
+	default:
+		target = shortestName(resolver, source, display, newTarget)
+	}
+
+	// Replace only the target so anchors, aliases and escaped pipes survive
+	raw := link.Raw
+	open := strings.Index(raw, "[[")
+	if open < 0 {
+		return ""
+	}
+	inner := raw[open+2:]
+	end := strings.IndexAny(inner, "#|\\]")
+	if end < 0 {
+		return ""
+	}
+	return raw[:open+2] + target + inner[end:]
+}
+
+// shortestName returns the shortest trailing part of display that resolves to file
+func shortestName(resolver *Resolver, source, display, file string) string {
+	parts := strings.Split(display, "/")
+	for i := len(parts) - 1; i > 0; i-- {
+		candidate := strings.Join(parts[i:], "/")
+		if resolver.resolveName(source, candidate) == file {
+			return candidate
+		}
+	}
+	return display
+}
+
+// rewriteMarkdownLink returns the markdown link text pointing at newTarget,
+// relative to the linking note unless the original was written from the vault
+// root. before is the resolver of the vault before the move.
+func rewriteMarkdownLink(before *Resolver, link types.NoteLink, source, newTarget string) string {
+	display := newTarget
+	if !strings.EqualFold(path.Ext(link.Target), path.Ext(newTarget)) {
+		display = strings.TrimSuffix(newTarget, path.Ext(newTarget))
+	}
+
+	var target string
+	switch {
+	case strings.HasPrefix(link.Target, "/"):
+		target = "/" + display
+	case before.exact(path.Join(path.Dir(link.Source), link.Target)) != link.Resolved:
+		// Written relative to the vault root rather than the linking note
+		target = display
+	default:
+		target = relativePath(path.Dir(source), display)
+	}
+
+	// Replace the destination path, keeping the link text, anchor and title
+	raw := link.Raw
+	open := strings.LastIndex(raw, "](")
+	if open < 0 {
+		return ""
+	}
+	destination := raw[open+2:]
+	leading := len(destination) - len(strings.TrimLeft(destination, " \t"))
+	destination = destination[leading:]
+
+	if strings.HasPrefix(destination, "<") {
+		end := strings.IndexAny(destination, "#>")
+		if end < 0 {
+			return ""
+		}
+		return raw[:open+2+leading] + "<" + target + destination[end:]
+	}
+
+	end := strings.IndexAny(destination, "# \t)")
+	if end < 0 {
+		return ""
+	}
+	return raw[:open+2+leading] + strings.ReplaceAll(target, " ", "%20") + destination[end:]
+}
+
+// relativePath returns the slash-separated path of target relative to dir
+func relativePath(dir, target string) string {
+	var from []string
+	if dir != "." && dir != "" {
+		from = strings.Split(dir, "/")
+	}
+	to := strings.Split(target, "/")
+
+	common := 0
+	for common < len(from) && common < len(to)-1 && from[common] == to[common] {
+		common++
+	}
+
+	parts := make([]string, 0, len(from)-common+len(to)-common)
+	for range from[common:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, to[common:]...)
+	return strings.Join(parts, "/")
+}
+
+// MovedFiles maps every file under src to its location under dst. A file
+// moves on its own; a folder moves with everything below it.
+func (g *Graph) MovedFiles(src, dst string) map[string]string {
+	moves := make(map[string]string)
+	src = strings.Trim(src, "/")
+	dst = strings.Trim(dst, "/")
+
+	for _, file := range g.files {
+		switch {
+		case file == src:
+			moves[file] = dst
+		case strings.HasPrefix(file, src+"/"):
+			moves[file] = dst + strings.TrimPrefix(file, src)
+		}
+	}
+	return moves
+}
@@ -0,0 +1,182 @@
+package links
+
+import (
+	"reflect"
+	"testing"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+func TestRewritesForMoves(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		notes map[string]string
+		src   string
+		dst   string
+		want  map[string][]types.LinkRewrite
+	}{
+		{
+			name:  "relative link to a moved target",
+			files: []string{"a/b/src.md", "t/target.md"},
+			notes: map[string]string{"a/b/src.md": "[x](../../t/target.md)\n"},
+			src:   "t/target.md",
+			dst:   "t/sub/target.md",
+			want: map[string][]types.LinkRewrite{
+				"a/b/src.md": {{Line: 1, From: "[x](../../t/target.md)", To: "[x](../../t/sub/target.md)"}},
+			},
+		},
+		{
+			name:  "relative link from a moved source",
+			files: []string{"a/src.md", "t/target.md"},
+			notes: map[string]string{"a/src.md": "see [x](../t/target.md) and [[../t/target]]\n"},
+			src:   "a/src.md",
+			dst:   "a/b/src.md",
+			want: map[string][]types.LinkRewrite{
+				"a/src.md": {
+					{Line: 1, From: "[x](../t/target.md)", To: "[x](../../t/target.md)"},
+					{Line: 1, From: "[[../t/target]]", To: "[[../../t/target]]"},
+				},
+			},
+		},
+		{
+			name:  "angle-bracket destination keeps its spaces",
+			files: []string{"src.md", "My Note.md"},
+			notes: map[string]string{"src.md": "[x](<My Note.md> \"title\")\n"},
+			src:   "My Note.md",
+			dst:   "f/My Note.md",
+			want: map[string][]types.LinkRewrite{
+				"src.md": {{Line: 1, From: "[x](<My Note.md> \"title\")", To: "[x](<f/My Note.md> \"title\")"}},
+			},
+		},
+		{
+			name:  "escaped destination stays escaped",
+			files: []string{"src.md", "My Note.md"},
+			notes: map[string]string{"src.md": "[x](My%20Note.md#Some%20Heading)\n"},
+			src:   "My Note.md",
+			dst:   "f/My Note.md",
+			want: map[string][]types.LinkRewrite{
+				"src.md": {{Line: 1, From: "[x](My%20Note.md#Some%20Heading)", To: "[x](f/My%20Note.md#Some%20Heading)"}},
+			},
+		},
+		{
+			name:  "anchors and aliases survive",
+			files: []string{"src.md", "t/target.md"},
+			notes: map[string]string{"src.md": "[[t/target#Heading|alias]] ![[t/target#^block]] [[t/target.md]]\n"},
+			src:   "t",
+			dst:   "u",
+			want: map[string][]types.LinkRewrite{
+				"src.md": {
+					{Line: 1, From: "[[t/target#Heading|alias]]", To: "[[u/target#Heading|alias]]"},
+					{Line: 1, From: "![[t/target#^block]]", To: "![[u/target#^block]]"},
+					{Line: 1, From: "[[t/target.md]]", To: "[[u/target.md]]"},
+				},
+			},
+		},
+		{
+			name:  "escaped pipe in a table",
+			files: []string{"src.md", "t/target.md"},
+			notes: map[string]string{"src.md": "| a | b |\n| - | - |\n| [[t/target\\|alias]] | x |\n"},
+			src:   "t",
+			dst:   "u",
+			want: map[string][]types.LinkRewrite{
+				"src.md": {{Line: 3, From: "[[t/target\\|alias]]", To: "[[u/target\\|alias]]"}},
+			},
+		},
+		{
+			name:  "bare name that still resolves is left alone",
+			files: []string{"src.md", "t/target.md"},
+			notes: map[string]string{"src.md": "[[target]]\n"},
+			src:   "t",
+			dst:   "u",
+			want:  map[string][]types.LinkRewrite{},
+		},
+		{
+			name:  "folder move makes a bare name ambiguous",
+			files: []string{"src.md", "one/Note.md", "two/Note.md"},
+			notes: map[string]string{"src.md": "[[Note|n]]\n"},
+			src:   "one",
+			dst:   "three/deep",
+			want: map[string][]types.LinkRewrite{
+				"src.md": {{Line: 1, From: "[[Note|n]]", To: "[[deep/Note|n]]"}},
+			},
+		},
+		{
+			name:  "folder move makes a bare name unique",
+			files: []string{"src.md", "one/Note.md", "two/Note.md"},
+			notes: map[string]string{"src.md": "[[two/Note]]\n"},
+			src:   "one",
+			dst:   "uno",
+			want:  map[string][]types.LinkRewrite{},
+		},
+		{
+			name:  "attachment keeps its extension",
+			files: []string{"src.md", "img/pic.png"},
+			notes: map[string]string{"src.md": "![[img/pic.png]] ![p](img/pic.png)\n"},
+			src:   "img",
+			dst:   "media",
+			want: map[string][]types.LinkRewrite{
+				"src.md": {
+					{Line: 1, From: "![[img/pic.png]]", To: "![[media/pic.png]]"},
+					{Line: 1, From: "![p](img/pic.png)", To: "![p](media/pic.png)"},
+				},
+			},
+		},
+		{
+			name:  "vault-rooted markdown link stays rooted",
+			files: []string{"a/src.md", "t/target.md"},
+			notes: map[string]string{"a/src.md": "[x](/t/target.md) [y](t/target.md)\n"},
+			src:   "t",
+			dst:   "u",
+			want: map[string][]types.LinkRewrite{
+				"a/src.md": {
+					{Line: 1, From: "[x](/t/target.md)", To: "[x](/u/target.md)"},
+					{Line: 1, From: "[y](t/target.md)", To: "[y](u/target.md)"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := Build(tt.files, tt.notes)
+			got := g.RewritesForMoves(g.MovedFiles(tt.src, tt.dst))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RewritesForMoves = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRewrites(t *testing.T) {
+	content := "[[a]] and [[a]]\n[[b]]\n"
+	rewrites := []types.LinkRewrite{
+		{Line: 1, From: "[[a]]", To: "[[x/a]]"},
+		{Line: 1, From: "[[a]]", To: "[[y/a]]"},
+		{Line: 3, From: "[[c]]", To: "[[d]]"},
+	}
+
+	got, applied := ApplyRewrites(content, rewrites)
+	if want := "[[x/a]] and [[y/a]]\n[[b]]\n"; got != want || applied != 2 {
+		t.Errorf("ApplyRewrites = %q, %d, want %q, 2", got, applied, want)
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		dir, target string
+		want        string
+	}{
+		{".", "a/b.md", "a/b.md"},
+		{"a", "a/b.md", "b.md"},
+		{"a/b", "c.md", "../../c.md"},
+		{"a/b", "a/c/d.md", "../c/d.md"},
+		{"a", "a", "../a"},
+	}
+
+	for _, tt := range tests {
+		if got := relativePath(tt.dir, tt.target); got != tt.want {
+			t.Errorf("relativePath(%q, %q) = %q, want %q", tt.dir, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,839 @@
+// Package markdown is a CommonMark + GFM block parser. It produces the flat
+// types.MarkdownElement list used by the structure and content tools, with
+// accurate 1-based line ranges for every block.
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// Element types produced by Parse
+const (
+	TypeFrontmatter   = "frontmatter"
+	TypeHeading       = "heading"
+	TypeParagraph     = "paragraph"
+	TypeList          = "list"
+	TypeListItem      = "list_item"
+	TypeCodeBlock     = "code_block"
+	TypeTable         = "table"
+	TypeBlockquote    = "blockquote"
+	TypeThematicBreak = "thematic_break"
+	TypeHTMLBlock     = "html_block"
+	TypeLink          = "link"
+	TypeImage         = "image"
+)
+
+// line is a source line as seen by the current container: container prefixes
+// such as "> " or list indentation are removed, the line number is kept
+type line struct {
+	text string
+	num  int // 1-based line number in the note
+}
+
+var (
+	atxHeadingPattern    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextPattern        = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	thematicBreakPattern = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fencePattern         = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})(.*)$")
+	blockquotePattern    = regexp.MustCompile(`^ {0,3}>`)
+	bulletPattern        = regexp.MustCompile(`^( {0,3})([-+*])([ \t]+|$)`)
+	orderedPattern       = regexp.MustCompile(`^( {0,3})(\d{1,9})([.)])([ \t]+|$)`)
+	taskPattern          = regexp.MustCompile(`^\[(.)\](?:[ \t]+|$)`)
+	tableDelimiterCell   = regexp.MustCompile(`^:?-+:?$`)
+	blockIDPattern       = regexp.MustCompile(`(?:^|\s)\^([A-Za-z0-9-]+)\s*$`)
+
+	imageOnlyPattern    = regexp.MustCompile(`^!\[([^\]]*)\]\(<?([^)\s>]*)>?(?:\s+"[^"]*")?\)$`)
+	linkOnlyPattern     = regexp.MustCompile(`^\[([^\]]+)\]\(<?([^)\s>]*)>?(?:\s+"[^"]*")?\)$`)
+	autolinkPattern     = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^<>\s]*)>$`)
+	linkDefinitionRegex = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:\s*<?(\S+?)>?(?:\s+["'(].*["')])?\s*$`)
+
+	htmlRawPattern     = regexp.MustCompile(`(?i)^ {0,3}<(script|pre|style|textarea)(\s|>|$)`)
+	htmlBlockTagRegex  = regexp.MustCompile(`(?i)^ {0,3}</?(address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[1-6]|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(\s|/?>|$)`)
+	htmlCompleteTagReg = regexp.MustCompile(`^ {0,3}(?:<[A-Za-z][A-Za-z0-9-]*(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*\s*/?>|</[A-Za-z][A-Za-z0-9-]*\s*>)\s*$`)
+)
+
+// Parse parses a note into its top-level blocks in document order. A leading
+// YAML frontmatter block is reported as a "frontmatter" element.
+func Parse(content string) []types.MarkdownElement {
+	raw := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if len(raw) > 1 && raw[len(raw)-1] == "" {
+		// The final newline ends the last line rather than starting an empty one
+		raw = raw[:len(raw)-1]
+	}
+	lines := make([]line, len(raw))
+	for i, text := range raw {
+		lines[i] = line{text: expandTabs(text), num: i + 1}
+	}
+
+	var elements []types.MarkdownElement
+	if frontmatter, next, ok := parseFrontmatter(lines); ok {
+		elements = append(elements, frontmatter)
+		lines = lines[next:]
+	}
+
+	return append(elements, parseBlocks(lines, 1)...)
+}
+
+// parseFrontmatter recognises a YAML block delimited by "---" lines at the top of the note
+func parseFrontmatter(lines []line) (types.MarkdownElement, int, bool) {
+	if len(lines) == 0 || strings.TrimRight(lines[0].text, " ") != "---" {
+		return types.MarkdownElement{}, 0, false
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if t := strings.TrimRight(lines[i].text, " "); t == "---" || t == "..." {
+			return types.MarkdownElement{
+				Type:    TypeFrontmatter,
+				Content: joinText(lines[1:i]),
+				Line:    lines[0].num,
+				EndLine: lines[i].num,
+			}, i + 1, true
+		}
+	}
+
+	return types.MarkdownElement{}, 0, false
+}
+
+// parseBlocks parses the blocks of one container; depth is the list nesting depth
+func parseBlocks(lines []line, depth int) []types.MarkdownElement {
+	var elements []types.MarkdownElement
+
+	i := 0
+	for i < len(lines) {
+		text := lines[i].text
+		if isBlank(text) {
+			i++
+			continue
+		}
+
+		var element types.MarkdownElement
+		switch {
+		case indentOf(text) >= 4:
+			element, i = parseIndentedCode(lines, i)
+		case fencePattern.MatchString(text) && isFenceOpen(text):
+			element, i = parseFencedCode(lines, i)
+		case atxHeadingPattern.MatchString(text):
+			element = parseATXHeading(lines[i])
+			i++
+		case thematicBreakPattern.MatchString(text):
+			element = types.MarkdownElement{Type: TypeThematicBreak, Content: text, Line: lines[i].num, EndLine: lines[i].num}
+			i++
+		case blockquotePattern.MatchString(text):
+			element, i = parseBlockquote(lines, i, depth)
+		case htmlBlockStart(text, false) != "":
+			element, i = parseHTMLBlock(lines, i)
+		case isMathOpen(text):
+			element, i = parseMathBlock(lines, i)
+		case footnoteDefPattern.MatchString(text):
+			element, i = parseFootnote(lines, i, depth)
+		case listMarkerAt(text).ok:
+			element, i = parseList(lines, i, depth)
+		default:
+			if end, ok := commentBlockEnd(lines, i); ok {
+				element, i = parseCommentBlock(lines, i, end)
+				break
+			}
+			var parsed []types.MarkdownElement
+			parsed, i = parseParagraph(lines, i)
+			elements = append(elements, parsed...)
+			continue
+		}
+		elements = append(elements, element)
+	}
+
+	return elements
+}
+
+// parseATXHeading parses a "## Title ##" heading line
+func parseATXHeading(l line) types.MarkdownElement {
+	match := atxHeadingPattern.FindStringSubmatch(l.text)
+	title := strings.TrimSpace(match[2])
+	return types.MarkdownElement{
+		Type:       TypeHeading,
+		Level:      len(match[1]),
+		Title:      title,
+		Line:       l.num,
+		EndLine:    l.num,
+		Attributes: map[string]string{"style": "atx"},
+		Children:   Inline(title, l.num),
+	}
+}
+
+// isFenceOpen reports whether a line opens a fenced code block; backtick
+// fences may not carry backticks in their info string
+func isFenceOpen(text string) bool {
+	match := fencePattern.FindStringSubmatch(text)
+	return match != nil && !(match[2][0] == '`' && strings.Contains(match[3], "`"))
+}
+
+// parseFencedCode parses a ``` or ~~~ block; an unclosed fence runs to the end of the container
+func parseFencedCode(lines []line, start int) (types.MarkdownElement, int) {
+	match := fencePattern.FindStringSubmatch(lines[start].text)
+	indent := len(match[1])
+	fence := match[2]
+	info := strings.TrimSpace(match[3])
+
+	language := info
+	if fields := strings.Fields(info); len(fields) > 0 {
+		language = fields[0]
+	}
+
+	element := types.MarkdownElement{
+		Type: TypeCodeBlock,
+		Line: lines[start].num,
+		Attributes: map[string]string{
+			"language": language,
+			"style":    "fenced",
+			"fence":    fence,
+		},
+	}
+	if info != "" && info != language {
+		element.Attributes["info"] = info
+	}
+
+	var body []string
+	i := start + 1
+	closed := false
+	for ; i < len(lines); i++ {
+		text := lines[i].text
+		if indentOf(text) < 4 {
+			trimmed := strings.TrimSpace(text)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				closed = true
+				break
+			}
+		}
+		body = append(body, stripIndent(text, indent))
+	}
+
+	element.Content = strings.Join(body, "\n")
+	if strings.EqualFold(language, "mermaid") {
+		mermaidAttributes(&element)
+	}
+	if closed {
+		element.EndLine = lines[i].num
+		return element, i + 1
+	}
+
+	element.EndLine = lines[len(lines)-1].num
+	element.Attributes["unclosed"] = "true"
+	return element, len(lines)
+}
+
+// parseIndentedCode parses a block of lines indented by four or more spaces
+func parseIndentedCode(lines []line, start int) (types.MarkdownElement, int) {
+	end := start
+	i := start
+	for ; i < len(lines); i++ {
+		text := lines[i].text
+		if isBlank(text) {
+			continue
+		}
+		if indentOf(text) < 4 {
+			break
+		}
+		end = i
+	}
+
+	var body []string
+	for _, l := range lines[start : end+1] {
+		body = append(body, stripIndent(l.text, 4))
+	}
+
+	return types.MarkdownElement{
+		Type:       TypeCodeBlock,
+		Content:    strings.Join(body, "\n"),
+		Line:       lines[start].num,
+		EndLine:    lines[end].num,
+		Attributes: map[string]string{"language": "", "style": "indented"},
+	}, end + 1
+}
+
+// parseBlockquote parses a "> " container, including lazy continuation lines
+func parseBlockquote(lines []line, start, depth int) (types.MarkdownElement, int) {
+	var inner []line
+	i := start
+	lazyAllowed := false
+	for ; i < len(lines); i++ {
+		text := lines[i].text
+		if blockquotePattern.MatchString(text) {
+			stripped := strings.TrimLeft(text, " ")[1:]
+			stripped = strings.TrimPrefix(stripped, " ")
+			inner = append(inner, line{text: stripped, num: lines[i].num})
+			lazyAllowed = continuesParagraph(stripped)
+			continue
+		}
+		if lazyAllowed && !isBlank(text) && !interruptsParagraph(text) {
+			inner = append(inner, line{text: text, num: lines[i].num})
+			continue
+		}
+		break
+	}
+
+	element := types.MarkdownElement{
+		Type:    TypeBlockquote,
+		Content: joinText(lines[start:i]),
+		Line:    lines[start].num,
+		EndLine: lines[i-1].num,
+	}
+	if !parseCallout(&element, inner, depth) {
+		element.Children = parseBlocks(inner, depth)
+	}
+	setBlockID(&element, lines[i-1].text)
+
+	return element, i
+}
+
+// continuesParagraph reports whether a line leaves an open paragraph behind,
+// which is what allows lazy continuation lines in containers
+func continuesParagraph(text string) bool {
+	return !isBlank(text) && indentOf(text) < 4 && !interruptsParagraph(text) && !setextPattern.MatchString(text)
+}
+
+// htmlBlockStart returns the end condition of an HTML block starting on this
+// line: a closing token, "blank" for blocks ending at a blank line, or "" when
+// the line does not start an HTML block. Complete single tags (CommonMark
+// type 7) cannot interrupt a paragraph.
+func htmlBlockStart(text string, inParagraph bool) string {
+	trimmed := strings.TrimLeft(text, " ")
+	if indentOf(text) > 3 || !strings.HasPrefix(trimmed, "<") {
+		return ""
+	}
+
+	if match := htmlRawPattern.FindStringSubmatch(text); match != nil {
+		return "</" + strings.ToLower(match[1]) + ">"
+	}
+
+	switch {
+	case strings.HasPrefix(trimmed, "<!--"):
+		return "-->"
+	case strings.HasPrefix(trimmed, "<?"):
+		return "?>"
+	case strings.HasPrefix(trimmed, "<![CDATA["):
+		return "]]>"
+	case len(trimmed) > 2 && trimmed[1] == '!' && trimmed[2] >= 'A' && trimmed[2] <= 'Z':
+		return ">"
+	case htmlBlockTagRegex.MatchString(text):
+		return "blank"
+	case !inParagraph && htmlCompleteTagReg.MatchString(text):
+		return "blank"
+	}
+
+	return ""
+}
+
+// parseHTMLBlock parses a raw HTML block
+func parseHTMLBlock(lines []line, start int) (types.MarkdownElement, int) {
+	end := htmlBlockStart(lines[start].text, false)
+
+	i := start
+	for ; i < len(lines); i++ {
+		text := lines[i].text
+		if end == "blank" {
+			if isBlank(text) {
+				break
+			}
+			continue
+		}
+		if strings.Contains(strings.ToLower(text), end) && (i > start || strings.Index(strings.ToLower(text), end) > 0) {
+			i++
+			break
+		}
+	}
+
+	return types.MarkdownElement{
+		Type:    TypeHTMLBlock,
+		Content: joinText(lines[start:i]),
+		Line:    lines[start].num,
+		EndLine: lines[i-1].num,
+	}, i
+}
+
+// listMarker describes the marker that opens a list item
+type listMarker struct {
+	ok            bool
+	ordered       bool
+	bullet        string // "-", "+" or "*" for bullet lists, "." or ")" for ordered lists
+	start         int
+	contentOffset int  // column where the item content starts
+	empty         bool // the marker line carries no content
+}
+
+// listMarkerAt parses the list marker at the start of a line
+func listMarkerAt(text string) listMarker {
+	if thematicBreakPattern.MatchString(text) {
+		return listMarker{}
+	}
+
+	var marker listMarker
+	var prefixLen int
+	if match := bulletPattern.FindStringSubmatch(text); match != nil {
+		marker = listMarker{ok: true, bullet: match[2]}
+		prefixLen = len(match[1]) + len(match[2])
+	} else if match := orderedPattern.FindStringSubmatch(text); match != nil {
+		start, _ := strconv.Atoi(match[2])
+		marker = listMarker{ok: true, ordered: true, bullet: match[3], start: start}
+		prefixLen = len(match[1]) + len(match[2]) + len(match[3])
+	} else {
+		return listMarker{}
+	}
+
+	rest := text[prefixLen:]
+	spaces := indentOf(rest)
+	marker.empty = isBlank(rest)
+	switch {
+	case marker.empty:
+		marker.contentOffset = prefixLen + 1
+	case spaces > 4:
+		// Content starting with 5+ spaces is indented code; the item content starts after one space
+		marker.contentOffset = prefixLen + 1
+	default:
+		marker.contentOffset = prefixLen + spaces
+	}
+
+	return marker
+}
+
+// sameList reports whether two markers belong to the same list
+func (m listMarker) sameList(other listMarker) bool {
+	return other.ok && m.ordered == other.ordered && m.bullet == other.bullet
+}
+
+// parseList parses consecutive items of one list
+func parseList(lines []line, start, depth int) (types.MarkdownElement, int) {
+	first := listMarkerAt(lines[start].text)
+
+	list := types.MarkdownElement{
+		Type:  TypeList,
+		Level: depth,
+		Line:  lines[start].num,
+		Attributes: map[string]string{
+			"ordered": strconv.FormatBool(first.ordered),
+			"marker":  first.bullet,
+		},
+	}
+	if first.ordered {
+		list.Attributes["start"] = strconv.Itoa(first.start)
+	}
+
+	loose := false
+	i := start
+	end := start
+	for i < len(lines) {
+		marker := listMarkerAt(lines[i].text)
+		if !first.sameList(marker) {
+			break
+		}
+
+		item, next, blankInside := parseListItem(lines, i, marker, depth)
+		list.Children = append(list.Children, item)
+		loose = loose || blankInside
+		end = next - 1
+
+		// Blank lines between items make the list loose; anything else ends it
+		j := next
+		for j < len(lines) && isBlank(lines[j].text) {
+			j++
+		}
+		if j < len(lines) && first.sameList(listMarkerAt(lines[j].text)) && indentOf(lines[j].text) < marker.contentOffset {
+			loose = loose || j > next
+			i = j
+			continue
+		}
+		i = next
+		break
+	}
+
+	list.Content = joinText(lines[start : end+1])
+	list.EndLine = lines[end].num
+	list.Attributes["items"] = strconv.Itoa(len(list.Children))
+	list.Attributes["loose"] = strconv.FormatBool(loose)
+	if len(list.Children) > 0 {
+		if id := list.Children[len(list.Children)-1].Attributes["block_id"]; id != "" {
+			list.Attributes["block_id"] = id
+		}
+	}
+
+	return list, end + 1
+}
+
+// parseListItem parses one list item and returns it, the index after its last
+// line and whether it contains blank lines between its blocks
+func parseListItem(lines []line, start int, marker listMarker, depth int) (types.MarkdownElement, int, bool) {
+	firstText := ""
+	if !marker.empty {
+		firstText = stripIndent(lines[start].text[min(marker.contentOffset, len(lines[start].text)):], 0)
+		if marker.contentOffset > len(lines[start].text) {
+			firstText = ""
+		}
+	}
+
+	content := []line{{text: firstText, num: lines[start].num}}
+	fence := openFence("", firstText)
+	lazyAllowed := fence == "" && continuesParagraph(firstText)
+
+	end := start
+	pendingBlank := 0
+	blankInside := false
+	i := start + 1
+	for ; i < len(lines); i++ {
+		text := lines[i].text
+		if isBlank(text) {
+			if marker.empty && end == start {
+				// An item can begin with at most one blank line
+				break
+			}
+			pendingBlank++
+			lazyAllowed = false
+			continue
+		}
+
+		if indentOf(text) >= marker.contentOffset {
+			for b := pendingBlank; b > 0; b-- {
+				content = append(content, line{text: "", num: lines[i-b].num})
+			}
+			blankInside = blankInside || pendingBlank > 0
+			pendingBlank = 0
+
+			stripped := stripIndent(text, marker.contentOffset)
+			content = append(content, line{text: stripped, num: lines[i].num})
+			fence = openFence(fence, stripped)
+			lazyAllowed = fence == "" && continuesParagraph(stripped)
+			end = i
+			continue
+		}
+
+		if pendingBlank == 0 && lazyAllowed && !interruptsParagraph(text) && !listMarkerAt(text).ok {
+			content = append(content, line{text: text, num: lines[i].num})
+			end = i
+			continue
+		}
+
+		break
+	}
+
+	item := types.MarkdownElement{
+		Type:       TypeListItem,
+		Level:      depth,
+		Content:    joinText(lines[start : end+1]),
+		Line:       lines[start].num,
+		EndLine:    lines[end].num,
+		Attributes: map[string]string{"marker": marker.bullet},
+	}
+	if marker.ordered {
+		item.Attributes["number"] = strconv.Itoa(marker.start)
+	}
+
+	title := strings.TrimSpace(firstText)
+	if match := taskPattern.FindStringSubmatch(title); match != nil {
+		item.Attributes["task"] = match[1]
+		title = strings.TrimSpace(title[len(match[0]):])
+		content[0].text = strings.TrimLeft(content[0].text, " ")[len(match[0]):]
+	}
+	item.Children = parseBlocks(content, depth+1)
+	setBlockID(&item, lines[end].text)
+	if id := item.Attributes["block_id"]; id != "" && end == start {
+		title = strings.TrimSpace(strings.TrimSuffix(title, "^"+id))
+	}
+	item.Title = title
+
+	return item, end + 1, blankInside
+}
+
+// openFence tracks fenced code while scanning container lines: it returns the
+// fence that is open after text, given the fence open before it
+func openFence(fence, text string) string {
+	trimmed := strings.TrimSpace(text)
+	if fence != "" {
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			return ""
+		}
+		return fence
+	}
+	if indentOf(text) < 4 && isFenceOpen(text) {
+		return fencePattern.FindStringSubmatch(text)[2]
+	}
+	return ""
+}
+
+// interruptsParagraph reports whether a line starts a block that can interrupt a paragraph
+func interruptsParagraph(text string) bool {
+	if indentOf(text) >= 4 {
+		return false
+	}
+
+	if atxHeadingPattern.MatchString(text) || thematicBreakPattern.MatchString(text) ||
+		blockquotePattern.MatchString(text) || isFenceOpen(text) || htmlBlockStart(text, true) != "" || isMathOpen(text) {
+		return true
+	}
+
+	// Only non-empty bullet items and ordered items starting at 1 interrupt a paragraph
+	if marker := listMarkerAt(text); marker.ok && !marker.empty {
+		return !marker.ordered || marker.start == 1
+	}
+
+	return false
+}
+
+// parseParagraph parses a paragraph and whatever it turns into: a setext
+// heading, a GFM table (possibly preceded by paragraph lines), a standalone
+// link or image, or a plain paragraph
+func parseParagraph(lines []line, start int) ([]types.MarkdownElement, int) {
+	i := start + 1
+	for ; i < len(lines); i++ {
+		text := lines[i].text
+		if isBlank(text) {
+			break
+		}
+
+		if isTableDelimiter(text) && isTableHeader(lines[i-1].text, text) {
+			var elements []types.MarkdownElement
+			if i-1 > start {
+				elements = append(elements, newParagraph(lines[start:i-1]))
+			}
+			table, next := parseTable(lines, i-1)
+			return append(elements, table), next
+		}
+
+		if match := setextPattern.FindStringSubmatch(text); match != nil {
+			level := 2
+			if match[1][0] == '=' {
+				level = 1
+			}
+			var titleLines []string
+			for _, l := range lines[start:i] {
+				titleLines = append(titleLines, strings.TrimSpace(l.text))
+			}
+			title := strings.Join(titleLines, " ")
+			return []types.MarkdownElement{{
+				Type:       TypeHeading,
+				Level:      level,
+				Title:      title,
+				Line:       lines[start].num,
+				EndLine:    lines[i].num,
+				Attributes: map[string]string{"style": "setext"},
+				Children:   Inline(title, lines[start].num),
+			}}, i + 1
+		}
+
+		if interruptsParagraph(text) {
+			break
+		}
+	}
+
+	return []types.MarkdownElement{newParagraph(lines[start:i])}, i
+}
+
+// newParagraph builds a paragraph element, classifying standalone links and images
+func newParagraph(lines []line) types.MarkdownElement {
+	element := types.MarkdownElement{
+		Type:    TypeParagraph,
+		Content: joinText(lines),
+		Line:    lines[0].num,
+		EndLine: lines[len(lines)-1].num,
+	}
+
+	setBlockID(&element, lines[len(lines)-1].text)
+
+	text := strings.TrimSpace(element.Content)
+	if id := element.Attributes["block_id"]; id != "" {
+		text = strings.TrimSpace(strings.TrimSuffix(text, "^"+id))
+	}
+	if standaloneLink(&element, text) {
+		return element
+	}
+
+	switch {
+	case imageOnlyPattern.MatchString(text):
+		match := imageOnlyPattern.FindStringSubmatch(text)
+		element.Type = TypeImage
+		element.Title = match[1]
+		element.Attributes = map[string]string{"src": match[2]}
+	case linkOnlyPattern.MatchString(text):
+		match := linkOnlyPattern.FindStringSubmatch(text)
+		element.Type = TypeLink
+		element.Title = match[1]
+		element.Attributes = map[string]string{"url": match[2]}
+	case autolinkPattern.MatchString(text):
+		match := autolinkPattern.FindStringSubmatch(text)
+		element.Type = TypeLink
+		element.Title = match[1]
+		element.Attributes = map[string]string{"url": match[1]}
+	case len(lines) == 1 && linkDefinitionRegex.MatchString(text):
+		match := linkDefinitionRegex.FindStringSubmatch(text)
+		element.Type = TypeLink
+		element.Title = match[1]
+		element.Attributes = map[string]string{"url": match[2], "definition": "true"}
+	default:
+		element.Children = Inline(element.Content, element.Line)
+	}
+
+	// Classification replaces the attributes, so record the block ID again
+	setBlockID(&element, lines[len(lines)-1].text)
+	return element
+}
+
+// isTableDelimiter reports whether a line is a GFM table delimiter row such as | :-- | --: |
+func isTableDelimiter(text string) bool {
+	if indentOf(text) >= 4 || !strings.Contains(text, "-") {
+		return false
+	}
+	cells := SplitTableRow(text)
+	if len(cells) == 0 {
+		return false
+	}
+	for _, cell := range cells {
+		if !tableDelimiterCell.MatchString(cell) {
+			return false
+		}
+	}
+	return strings.Contains(text, "|") || len(cells) > 1
+}
+
+// isTableHeader reports whether header has as many cells as the delimiter row
+func isTableHeader(header, delimiter string) bool {
+	return strings.Contains(header, "|") && indentOf(header) < 4 &&
+		len(SplitTableRow(header)) == len(SplitTableRow(delimiter))
+}
+
+// parseTable parses a GFM table starting at its header row
+func parseTable(lines []line, start int) (types.MarkdownElement, int) {
+	header := SplitTableRow(lines[start].text)
+	delimiter := SplitTableRow(lines[start+1].text)
+
+	alignments := make([]string, len(delimiter))
+	for i, cell := range delimiter {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			alignments[i] = "center"
+		case strings.HasSuffix(cell, ":"):
+			alignments[i] = "right"
+		case strings.HasPrefix(cell, ":"):
+			alignments[i] = "left"
+		default:
+			alignments[i] = "none"
+		}
+	}
+
+	i := start + 2
+	for ; i < len(lines); i++ {
+		text := lines[i].text
+		if isBlank(text) || interruptsParagraph(text) {
+			break
+		}
+	}
+
+	element := types.MarkdownElement{
+		Type:    TypeTable,
+		Content: joinText(lines[start:i]),
+		Line:    lines[start].num,
+		EndLine: lines[i-1].num,
+		Attributes: map[string]string{
+			"columns":    strings.Join(header, "|"),
+			"alignments": strings.Join(alignments, ","),
+			"rows":       strconv.Itoa(i - start - 2),
+		},
+	}
+	element.Children = Inline(element.Content, element.Line)
+	setBlockID(&element, lines[i-1].text)
+
+	return element, i
+}
+
+// SplitTableRow splits a table row into trimmed cells, honouring escaped pipes
+// and pipes inside inline code
+func SplitTableRow(text string) []string {
+	row := strings.TrimSpace(text)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = strings.TrimSuffix(row, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(row); i++ {
+		c := row[i]
+		switch {
+		case c == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteString(`\|`)
+			i++
+		case c == '`':
+			inCode = !inCode
+			cell.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// setBlockID records a trailing ^block-id marker on the element
+func setBlockID(element *types.MarkdownElement, lastLine string) {
+	match := blockIDPattern.FindStringSubmatch(lastLine)
+	if match == nil {
+		return
+	}
+	if element.Attributes == nil {
+		element.Attributes = map[string]string{}
+	}
+	element.Attributes["block_id"] = match[1]
+}
+
+// expandTabs replaces tabs in the leading whitespace of a line with spaces up to 4-column tab stops
+func expandTabs(text string) string {
+	if !strings.Contains(text, "\t") {
+		return text
+	}
+
+	var buf strings.Builder
+	column := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\t':
+			spaces := 4 - column%4
+			buf.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		case ' ':
+			buf.WriteByte(' ')
+			column++
+		default:
+			buf.WriteString(text[i:])
+			return buf.String()
+		}
+	}
+	return buf.String()
+}
+
+// indentOf returns the number of leading spaces
+func indentOf(text string) int {
+	return len(text) - len(strings.TrimLeft(text, " "))
+}
+
+// stripIndent removes up to n leading spaces
+func stripIndent(text string, n int) string {
+	for n > 0 && strings.HasPrefix(text, " ") {
+		text = text[1:]
+		n--
+	}
+	return text
+}
+
+func isBlank(text string) bool {
+	return strings.TrimSpace(text) == ""
+}
+
+func joinText(lines []line) string {
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.text
+	}
+	return strings.Join(texts, "\n")
+}
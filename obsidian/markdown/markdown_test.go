@@ -0,0 +1,152 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// outline renders the element tree as "type line-endLine" rows, indenting
+// children by two spaces
+func outline(elements []types.MarkdownElement) string {
+	var rows []string
+	var walk func(elements []types.MarkdownElement, indent string)
+	walk = func(elements []types.MarkdownElement, indent string) {
+		for _, element := range elements {
+			rows = append(rows, fmt.Sprintf("%s%s %d-%d", indent, element.Type, element.Line, element.EndLine))
+			walk(element.Children, indent+"  ")
+		}
+	}
+	walk(elements, "")
+	return strings.Join(rows, "\n")
+}
+
+// parseTest is a note and the outline Parse should produce for it
+type parseTest struct {
+	name    string
+	content string
+	want    []string
+}
+
+func runParseTests(t *testing.T, tests []parseTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := outline(Parse(tt.content))
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("Parse(%q) =\n%s\nwant\n%s", tt.content, got, want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{
+			name:    "tilde fence ignores backtick fence",
+			content: "~~~python\ncode\n```\n~~~\nafter\n",
+			want: []string{
+				"code_block 1-4",
+				"paragraph 5-5",
+			},
+		},
+		{
+			name:    "unclosed fence runs to the end",
+			content: "text\n```\nunclosed\n\n# not a heading\n",
+			want: []string{
+				"paragraph 1-1",
+				"code_block 2-5",
+			},
+		},
+		{
+			name:    "setext headings",
+			content: "Title\n=====\n\nSub\nline two\n---\n",
+			want: []string{
+				"heading 1-2",
+				"heading 4-6",
+			},
+		},
+		{
+			name:    "indented code does not interrupt a paragraph",
+			content: "    indented\n    code\n\npara\n    not code\n",
+			want: []string{
+				"code_block 1-2",
+				"paragraph 4-5",
+			},
+		},
+		{
+			name:    "lazy continuation lines",
+			content: "para one\nlazy\n> quote\ncontinued lazily\n\n- item\nlazy item\n",
+			want: []string{
+				"paragraph 1-2",
+				"blockquote 3-4",
+				"  paragraph 3-4",
+				"list 6-7",
+				"  list_item 6-7",
+				"    paragraph 6-7",
+			},
+		},
+		{
+			name:    "nested lists and blockquotes",
+			content: "- a\n  - b\n    > quote in b\n    > more\n  - c\n- d\n\n> outer\n> > inner\n> back\n",
+			want: []string{
+				"list 1-6",
+				"  list_item 1-5",
+				"    paragraph 1-1",
+				"    list 2-5",
+				"      list_item 2-4",
+				"        paragraph 2-2",
+				"        blockquote 3-4",
+				"          paragraph 3-4",
+				"      list_item 5-5",
+				"        paragraph 5-5",
+				"  list_item 6-6",
+				"    paragraph 6-6",
+				"blockquote 8-10",
+				"  paragraph 8-8",
+				"  blockquote 9-10",
+				"    paragraph 9-10",
+			},
+		},
+		{
+			name:    "tags and shebangs are not headings",
+			content: "#tag at the start\n#!shebang\n# Real\n",
+			want: []string{
+				"paragraph 1-2",
+				"heading 3-3",
+			},
+		},
+		{
+			name:    "frontmatter and CRLF",
+			content: "---\r\ntitle: x\r\n---\r\n# A\r\nbody\r\n",
+			want: []string{
+				"frontmatter 1-3",
+				"heading 4-4",
+				"paragraph 5-5",
+			},
+		},
+	})
+}
+
+func TestParseHeadingStyle(t *testing.T) {
+	elements := Parse("Title\n=====\n## Sub ##\n")
+	if len(elements) != 2 {
+		t.Fatalf("Parse returned %d elements, want 2", len(elements))
+	}
+
+	want := []struct {
+		title, style string
+		level        int
+	}{
+		{"Title", "setext", 1},
+		{"Sub", "atx", 2},
+	}
+	for i, w := range want {
+		got := elements[i]
+		if got.Title != w.title || got.Attributes["style"] != w.style || got.Level != w.level {
+			t.Errorf("heading %d = %q (%s, level %d), want %q (%s, level %d)", i, got.Title, got.Attributes["style"], got.Level, w.title, w.style, w.level)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package markdown
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+// Obsidian-flavoured element types produced by Parse. Wikilinks, embeds,
+// highlights, comments and footnote references found inside paragraphs,
+// headings and tables are reported as children of those blocks.
+const (
+	TypeCallout     = "callout"
+	TypeEmbed       = "embed"
+	TypeWikilink    = "wikilink"
+	TypeComment     = "comment"
+	TypeHighlight   = "highlight"
+	TypeMathBlock   = "math_block"
+	TypeFootnote    = "footnote"
+	TypeFootnoteRef = "footnote_ref"
+	TypeMermaid     = "mermaid"
+)
+
+var obsidianTypes = map[string]bool{
+	TypeCallout:     true,
+	TypeEmbed:       true,
+	TypeWikilink:    true,
+	TypeComment:     true,
+	TypeHighlight:   true,
+	TypeMathBlock:   true,
+	TypeFootnote:    true,
+	TypeFootnoteRef: true,
+	TypeMermaid:     true,
+}
+
+var (
+	calloutPattern      = regexp.MustCompile(`^\[!([^\]\s]+)\]([+-]?)(?:[ \t]+(.*?))?[ \t]*$`)
+	footnoteDefPattern  = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:[ \t]?(.*)$`)
+	embedOnlyPattern    = regexp.MustCompile(`^!\[\[([^\[\]]+)\]\]$`)
+	wikilinkOnlyPattern = regexp.MustCompile(`^\[\[([^\[\]]+)\]\]$`)
+	embedSizePattern    = regexp.MustCompile(`^\d+(?:x\d+)?$`)
+)
+
+var embedKinds = map[string]string{
+	".png": "image", ".jpg": "image", ".jpeg": "image", ".gif": "image", ".bmp": "image", ".svg": "image", ".webp": "image", ".avif": "image",
+	".mp3": "audio", ".wav": "audio", ".m4a": "audio", ".ogg": "audio", ".flac": "audio", ".3gp": "audio", ".webm": "audio",
+	".mp4": "video", ".mkv": "video", ".mov": "video", ".ogv": "video",
+	".pdf": "pdf",
+}
+
+// IsObsidianType reports whether an element type is Obsidian-specific syntax
+// rather than CommonMark/GFM
+func IsObsidianType(elementType string) bool {
+	return obsidianTypes[elementType]
+}
+
+// Walk calls fn for every element and, depth first, for all of its children
+func Walk(elements []types.MarkdownElement, fn func(types.MarkdownElement)) {
+	for _, element := range elements {
+		fn(element)
+		Walk(element.Children, fn)
+	}
+}
+
+// Wikilink is the parsed form of [[target#heading|alias]], [[target#^block]]
+// or an ![[embed]]
+type Wikilink struct {
+	Target  string // note or file name; empty for links within the same note
+	Heading string // heading subpath, nested headings joined with "#"
+	Block   string // block ID without the ^ prefix
+	Alias   string // display text after the pipe
+	Embed   bool
+}
+
+// ParseWikilink parses the text between [[ and ]]. Pipes escaped for use in
+// tables (\|) are accepted.
+func ParseWikilink(inner string) Wikilink {
+	var link Wikilink
+
+	inner = strings.ReplaceAll(inner, `\|`, "|")
+	if i := strings.Index(inner, "|"); i >= 0 {
+		link.Alias = strings.TrimSpace(inner[i+1:])
+		inner = inner[:i]
+	}
+
+	if i := strings.Index(inner, "#"); i >= 0 {
+		subpath := strings.TrimSpace(inner[i+1:])
+		inner = inner[:i]
+		if strings.HasPrefix(subpath, "^") {
+			link.Block = strings.TrimPrefix(subpath, "^")
+		} else {
+			link.Heading = subpath
+		}
+	}
+
+	link.Target = strings.TrimSpace(inner)
+	return link
+}
+
+// String renders the link back to wikilink syntax
+func (w Wikilink) String() string {
+	var buf strings.Builder
+	if w.Embed {
+		buf.WriteString("!")
+	}
+	buf.WriteString("[[")
+	buf.WriteString(w.Target)
+	if w.Heading != "" {
+		buf.WriteString("#" + w.Heading)
+	}
+	if w.Block != "" {
+		buf.WriteString("#^" + w.Block)
+	}
+	if w.Alias != "" {
+		buf.WriteString("|" + w.Alias)
+	}
+	buf.WriteString("]]")
+	return buf.String()
+}
+
+// element converts the link to a wikilink or embed element
+func (w Wikilink) element(raw string, startLine, endLine int) types.MarkdownElement {
+	element := types.MarkdownElement{
+		Type:       TypeWikilink,
+		Content:    raw,
+		Line:       startLine,
+		EndLine:    endLine,
+		Attributes: map[string]string{"target": w.Target},
+	}
+
+	if w.Heading != "" {
+		element.Attributes["heading"] = w.Heading
+	}
+	if w.Block != "" {
+		element.Attributes["block"] = w.Block
+	}
+
+	element.Title = w.Target
+	if w.Embed {
+		element.Type = TypeEmbed
+		kind := embedKinds[strings.ToLower(path.Ext(w.Target))]
+		if kind == "" {
+			kind = "note"
+		}
+		element.Attributes["kind"] = kind
+		if kind == "image" && embedSizePattern.MatchString(w.Alias) {
+			element.Attributes["size"] = w.Alias
+			return element
+		}
+	}
+
+	if w.Alias != "" {
+		element.Attributes["alias"] = w.Alias
+		element.Title = w.Alias
+	}
+	return element
+}
+
+// parseCallout turns a blockquote whose first line is [!type] into a callout
+func parseCallout(element *types.MarkdownElement, inner []line, depth int) bool {
+	if len(inner) == 0 {
+		return false
+	}
+
+	match := calloutPattern.FindStringSubmatch(strings.TrimSpace(inner[0].text))
+	if match == nil {
+		return false
+	}
+
+	calloutType := strings.ToLower(match[1])
+	title := match[3]
+	if title == "" {
+		title = strings.ToUpper(calloutType[:1]) + calloutType[1:]
+	}
+
+	element.Type = TypeCallout
+	element.Title = title
+	if element.Attributes == nil {
+		element.Attributes = map[string]string{}
+	}
+	element.Attributes["callout_type"] = calloutType
+	switch match[2] {
+	case "+":
+		element.Attributes["fold"] = "open"
+	case "-":
+		element.Attributes["fold"] = "closed"
+	}
+	element.Children = parseBlocks(inner[1:], depth)
+
+	return true
+}
+
+// isMathOpen reports whether a line opens a $$ math block
+func isMathOpen(text string) bool {
+	return indentOf(text) < 4 && strings.HasPrefix(strings.TrimSpace(text), "$$")
+}
+
+// parseMathBlock parses a $$ ... $$ block, which may sit on a single line
+func parseMathBlock(lines []line, start int) (types.MarkdownElement, int) {
+	first := strings.TrimPrefix(strings.TrimSpace(lines[start].text), "$$")
+
+	element := types.MarkdownElement{
+		Type: TypeMathBlock,
+		Line: lines[start].num,
+	}
+
+	if strings.HasSuffix(first, "$$") {
+		element.Content = strings.TrimSpace(strings.TrimSuffix(first, "$$"))
+		element.EndLine = lines[start].num
+		return element, start + 1
+	}
+
+	var body []string
+	if strings.TrimSpace(first) != "" {
+		body = append(body, first)
+	}
+
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i].text)
+		if strings.HasSuffix(trimmed, "$$") {
+			if rest := strings.TrimSuffix(trimmed, "$$"); rest != "" {
+				body = append(body, rest)
+			}
+			element.Content = strings.Join(body, "\n")
+			element.EndLine = lines[i].num
+			return element, i + 1
+		}
+		body = append(body, lines[i].text)
+	}
+
+	element.Content = strings.Join(body, "\n")
+	element.EndLine = lines[len(lines)-1].num
+	element.Attributes = map[string]string{"unclosed": "true"}
+	return element, len(lines)
+}
+
+// commentBlockEnd reports whether a %% comment block starts at lines[start]
+// and returns the index of its last line. A comment that closes mid-line
+// followed by more text is inline and left to the paragraph.
+func commentBlockEnd(lines []line, start int) (int, bool) {
+	trimmed := strings.TrimSpace(lines[start].text)
+	if indentOf(lines[start].text) >= 4 || !strings.HasPrefix(trimmed, "%%") {
+		return 0, false
+	}
+
+	rest := trimmed[2:]
+	if i := strings.Index(rest, "%%"); i >= 0 {
+		return start, strings.TrimSpace(rest[i+2:]) == ""
+	}
+
+	for i := start + 1; i < len(lines); i++ {
+		if j := strings.Index(lines[i].text, "%%"); j >= 0 {
+			return i, strings.TrimSpace(lines[i].text[j+2:]) == ""
+		}
+	}
+	return len(lines) - 1, true
+}
+
+// parseCommentBlock parses a %% comment %% block ending at end
+func parseCommentBlock(lines []line, start, end int) (types.MarkdownElement, int) {
+	text := strings.TrimPrefix(strings.TrimSpace(joinText(lines[start:end+1])), "%%")
+	text = strings.TrimSuffix(text, "%%")
+
+	return types.MarkdownElement{
+		Type:    TypeComment,
+		Content: strings.TrimSpace(text),
+		Line:    lines[start].num,
+		EndLine: lines[end].num,
+	}, end + 1
+}
+
+// parseFootnote parses a [^id]: definition and its indented continuation lines
+func parseFootnote(lines []line, start, depth int) (types.MarkdownElement, int) {
+	match := footnoteDefPattern.FindStringSubmatch(lines[start].text)
+	inner := []line{{text: match[2], num: lines[start].num}}
+
+	end := start
+	pendingBlank := 0
+	lazyAllowed := continuesParagraph(match[2])
+	for i := start + 1; i < len(lines); i++ {
+		text := lines[i].text
+		if isBlank(text) {
+			pendingBlank++
+			lazyAllowed = false
+			continue
+		}
+		if indentOf(text) >= 4 {
+			for b := pendingBlank; b > 0; b-- {
+				inner = append(inner, line{text: "", num: lines[i-b].num})
+			}
+			pendingBlank = 0
+			inner = append(inner, line{text: stripIndent(text, 4), num: lines[i].num})
+			lazyAllowed = continuesParagraph(stripIndent(text, 4))
+			end = i
+			continue
+		}
+		if pendingBlank == 0 && lazyAllowed && !interruptsParagraph(text) && !footnoteDefPattern.MatchString(text) {
+			inner = append(inner, line{text: text, num: lines[i].num})
+			end = i
+			continue
+		}
+		break
+	}
+
+	element := types.MarkdownElement{
+		Type:       TypeFootnote,
+		Title:      match[1],
+		Content:    strings.TrimSpace(joinText(inner)),
+		Line:       lines[start].num,
+		EndLine:    lines[end].num,
+		Attributes: map[string]string{"id": match[1]},
+		Children:   parseBlocks(inner, depth),
+	}
+
+	return element, end + 1
+}
+
+// mermaidAttributes describes the diagram in a mermaid fence
+func mermaidAttributes(element *types.MarkdownElement) {
+	element.Type = TypeMermaid
+	inFrontmatter := false
+	for i, text := range strings.Split(element.Content, "\n") {
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "---" && (i == 0 || inFrontmatter) {
+			inFrontmatter = !inFrontmatter
+			continue
+		}
+		if inFrontmatter || trimmed == "" || strings.HasPrefix(trimmed, "%%") {
+			continue
+		}
+
+		fields := strings.Fields(trimmed)
+		element.Attributes["diagram"] = fields[0]
+		if (fields[0] == "graph" || fields[0] == "flowchart") && len(fields) > 1 {
+			element.Attributes["direction"] = strings.TrimSuffix(fields[1], ";")
+		}
+		return
+	}
+}
+
+// standaloneLink classifies a paragraph that consists of a single wikilink or embed
+func standaloneLink(element *types.MarkdownElement, text string) bool {
+	var link Wikilink
+	if match := embedOnlyPattern.FindStringSubmatch(text); match != nil {
+		link = ParseWikilink(match[1])
+		link.Embed = true
+	} else if match := wikilinkOnlyPattern.FindStringSubmatch(text); match != nil {
+		link = ParseWikilink(match[1])
+	} else {
+		return false
+	}
+
+	attributes := element.Attributes
+	*element = link.element(text, element.Line, element.EndLine)
+	for key, value := range attributes {
+		element.Attributes[key] = value
+	}
+	return true
+}
+
+// Inline returns the wikilinks, embeds, highlights, %%comments%%, footnote
+// references and markdown links and images in a span of text that starts on
+// firstLine. Code spans are skipped.
+func Inline(text string, firstLine int) []types.MarkdownElement {
+	var elements []types.MarkdownElement
+
+	lineAt := func(offset int) int {
+		return firstLine + strings.Count(text[:offset], "\n")
+	}
+	add := func(element types.MarkdownElement, start, end int) {
+		element.Content = text[start:end]
+		element.Line = lineAt(start)
+		element.EndLine = lineAt(end)
+		elements = append(elements, element)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1:
+			i += 2
+			continue
+		case rest[0] == '`':
+			run := len(rest) - len(strings.TrimLeft(rest, "`"))
+			if end := strings.Index(rest[run:], rest[:run]); end >= 0 {
+				i += run + end + run
+			} else {
+				i += run
+			}
+			continue
+		case strings.HasPrefix(rest, "%%"):
+			end := strings.Index(rest[2:], "%%")
+			if end < 0 {
+				add(types.MarkdownElement{Type: TypeComment, Title: strings.TrimSpace(rest[2:])}, i, len(text))
+				i = len(text)
+				continue
+			}
+			add(types.MarkdownElement{Type: TypeComment, Title: strings.TrimSpace(rest[2 : 2+end])}, i, i+end+4)
+			i += end + 4
+			continue
+		case strings.HasPrefix(rest, "![["), strings.HasPrefix(rest, "[["):
+			embed := rest[0] == '!'
+			open := 2
+			if embed {
+				open = 3
+			}
+			if end := strings.Index(rest[open:], "]]"); end > 0 && !strings.Contains(rest[open:open+end], "\n") {
+				link := ParseWikilink(rest[open : open+end])
+				link.Embed = embed
+				element := link.element("", 0, 0)
+				add(element, i, i+open+end+2)
+				i += open + end + 2
+				continue
+			}
+		case strings.HasPrefix(rest, "=="):
+			if end := strings.Index(rest[2:], "=="); end > 0 && strings.TrimSpace(rest[2:2+end]) != "" {
+				add(types.MarkdownElement{Type: TypeHighlight, Title: rest[2 : 2+end]}, i, i+end+4)
+				i += end + 4
+				continue
+			}
+		case strings.HasPrefix(rest, "[^"):
+			if end := strings.Index(rest, "]"); end > 2 && !strings.ContainsAny(rest[2:end], " \t\n") {
+				id := rest[2:end]
+				add(types.MarkdownElement{Type: TypeFootnoteRef, Title: id, Attributes: map[string]string{"id": id}}, i, i+end+1)
+				i += end + 1
+				continue
+			}
+		case strings.HasPrefix(rest, "!["), strings.HasPrefix(rest, "["):
+			if label, destination, n, ok := inlineLink(rest); ok {
+				element := types.MarkdownElement{Type: TypeLink, Title: label, Attributes: map[string]string{"url": destination}}
+				if rest[0] == '!' {
+					element = types.MarkdownElement{Type: TypeImage, Title: label, Attributes: map[string]string{"src": destination}}
+				}
+				add(element, i, i+n)
+				// Link text may itself hold an image, as in [![alt](img.png)](url)
+				elements = append(elements, Inline(label, lineAt(i))...)
+				i += n
+				continue
+			}
+		case strings.HasPrefix(rest, "^["):
+			if end := strings.Index(rest, "]"); end > 2 {
+				note := rest[2:end]
+				add(types.MarkdownElement{Type: TypeFootnoteRef, Title: note, Attributes: map[string]string{"inline": "true"}}, i, i+end+1)
+				i += end + 1
+				continue
+			}
+		}
+		i++
+	}
+
+	return elements
+}
+
+// inlineLink parses [label](destination "title") or ![alt](src) at the start
+// of text, returning the label, destination and length of the link
+func inlineLink(text string) (string, string, int, bool) {
+	start := strings.Index(text, "[")
+	depth := 0
+	end := -1
+	for i := start; i < len(text) && end < 0; i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end < 0 || end+1 >= len(text) || text[end+1] != '(' {
+		return "", "", 0, false
+	}
+	label := text[start+1 : end]
+
+	i := end + 2
+	for i < len(text) && (text[i] == ' ' || text[i] == '\t') {
+		i++
+	}
+
+	var destination string
+	if i < len(text) && text[i] == '<' {
+		closing := strings.IndexAny(text[i:], ">\n")
+		if closing < 0 || text[i+closing] != '>' {
+			return "", "", 0, false
+		}
+		destination = text[i+1 : i+closing]
+		i += closing + 1
+	} else {
+		parens := 0
+		begin := i
+		for ; i < len(text); i++ {
+			c := text[i]
+			if c == ' ' || c == '\t' || c == '\n' || (c == ')' && parens == 0) {
+				break
+			}
+			if c == '(' {
+				parens++
+			} else if c == ')' {
+				parens--
+			}
+		}
+		destination = text[begin:i]
+	}
+
+	// Skip an optional title
+	closing := strings.Index(text[i:], ")")
+	if closing < 0 {
+		return "", "", 0, false
+	}
+	if title := strings.TrimSpace(text[i : i+closing]); title != "" && !strings.ContainsAny(title[:1], "\"'(") {
+		return "", "", 0, false
+	}
+
+	return label, destination, i + closing + 1, true
+}
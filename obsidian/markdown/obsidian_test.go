@@ -0,0 +1,39 @@
+package markdown
+
+import "testing"
+
+func TestParseObsidian(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{
+			name:    "callout with a task",
+			content: "> [!note] Title\n> body\n> - [ ] task\n\nafter\n",
+			want: []string{
+				"callout 1-3",
+				"  paragraph 2-2",
+				"  list 3-3",
+				"    list_item 3-3",
+				"      paragraph 3-3",
+				"paragraph 5-5",
+			},
+		},
+		{
+			name:    "footnote with continuation",
+			content: "text[^1]\n\n[^1]: the note\n    continued\n\nafter\n",
+			want: []string{
+				"paragraph 1-1",
+				"  footnote_ref 1-1",
+				"footnote 3-4",
+				"  paragraph 3-4",
+				"paragraph 6-6",
+			},
+		},
+		{
+			name:    "math and comment blocks",
+			content: "$$\nx\n$$\n%%\nhidden\n%%\n",
+			want: []string{
+				"math_block 1-3",
+				"comment 4-6",
+			},
+		},
+	})
+}
@@ -0,0 +1,157 @@
+package patch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrHashMismatch is returned when the lines of a lines target no longer hold the expected content
+var ErrHashMismatch = errors.New("lines changed since they were read")
+
+// hashLength is the number of hex digits of a line hash
+const hashLength = 16
+
+// ParseLineRange parses a lines target such as "12" or "12-15" into a
+// 1-based inclusive range
+func ParseLineRange(target string) (int, int, error) {
+	from, to, isRange := strings.Cut(strings.TrimSpace(target), "-")
+	start, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line range %q, expected a line such as 12 or a range such as 12-15", target)
+	}
+	end := start
+	if isRange {
+		if end, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+			return 0, 0, fmt.Errorf("invalid line range %q, expected a line such as 12 or a range such as 12-15", target)
+		}
+	}
+	if start < 1 || end < start {
+		return 0, 0, fmt.Errorf("invalid line range %q, lines start at 1 and the range must not be reversed", target)
+	}
+	return start, end, nil
+}
+
+// FormatLineRange describes a 1-based line range as "line 4" or "lines 4-6"
+func FormatLineRange(start, end int) string {
+	if start == end {
+		return fmt.Sprintf("line %d", start)
+	}
+	return fmt.Sprintf("lines %d-%d", start, end)
+}
+
+// LineHash returns the first 16 hex characters of the SHA-256 of lines joined
+// with "\n", ignoring \r line endings, for guarding a lines target against
+// concurrent edits. Tool descriptions document the algorithm so clients can
+// compute it from lines they read.
+func LineHash(lines []string) string {
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		trimmed[i] = strings.TrimSuffix(line, "\r")
+	}
+	sum := sha256.Sum256([]byte(strings.Join(trimmed, "\n")))
+	return hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// ApplyLines patches a 1-based line range given as "start" or "start-end".
+// prepend and append insert before and after the range, replace and delete
+// replace or remove it. When expectedHash is set the range must still hash
+// to it, see LineHash.
+func ApplyLines(content, operation, target, expectedHash, body string) (string, error) {
+	start, end, err := ParseLineRange(target)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(content, "\n")
+	total := len(lines)
+	if total > 1 && lines[total-1] == "" {
+		// A trailing newline does not start another line
+		total--
+	}
+	if end > total {
+		return "", fmt.Errorf("%w: %s, the note has %d lines", ErrTargetNotFound, FormatLineRange(start, end), total)
+	}
+
+	if expectedHash != "" {
+		if actual := LineHash(lines[start-1 : end]); !strings.EqualFold(strings.TrimSpace(expectedHash), actual) {
+			return "", fmt.Errorf("%w: the hash of %s is now %s", ErrHashMismatch, FormatLineRange(start, end), actual)
+		}
+	}
+
+	insert := splitBody(body)
+	if lineEnd := lines[start-1]; strings.HasSuffix(lineEnd, "\r") {
+		for i := range insert {
+			insert[i] = strings.TrimSuffix(insert[i], "\r") + "\r"
+		}
+	}
+
+	switch operation {
+	case "prepend":
+		lines = spliceLines(lines, start-1, start-1, insert)
+	case "append":
+		lines = spliceLines(lines, end, end, insert)
+	case "replace":
+		lines = spliceLines(lines, start-1, end, insert)
+	case "delete":
+		lines = spliceLines(lines, start-1, end, nil)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// ApplyRegex patches the occurrence-th match (1-based, -1 for the last) of a
+// regular expression. replace and delete replace or remove the matched text;
+// prepend and append insert lines before the line where the match starts or
+// after the line where it ends. ^ and $ match at line boundaries.
+func ApplyRegex(content, operation, pattern string, occurrence int, body string) (string, error) {
+	re, err := regexp.Compile("(?m)" + pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex %q: %v", pattern, err)
+	}
+
+	matches := re.FindAllStringIndex(content, -1)
+	// Empty matches cannot anchor an edit
+	found := matches[:0]
+	for _, match := range matches {
+		if match[1] > match[0] {
+			found = append(found, match)
+		}
+	}
+	if len(found) == 0 {
+		return "", fmt.Errorf("%w: no match for regex %q", ErrTargetNotFound, pattern)
+	}
+
+	index := occurrence - 1
+	if occurrence == -1 {
+		index = len(found) - 1
+	}
+	if occurrence == 0 || index < 0 || index >= len(found) {
+		return "", fmt.Errorf("%w: occurrence %d of regex %q, it matches %d times", ErrTargetNotFound, occurrence, pattern, len(found))
+	}
+	match := found[index]
+
+	switch operation {
+	case "replace":
+		return content[:match[0]] + body + content[match[1]:], nil
+	case "delete":
+		return content[:match[0]] + content[match[1]:], nil
+	}
+
+	lines := strings.Split(content, "\n")
+	insert := splitBody(body)
+	switch operation {
+	case "prepend":
+		line := strings.Count(content[:match[0]], "\n")
+		lines = spliceLines(lines, line, line, insert)
+	case "append":
+		line := strings.Count(content[:match[1]-1], "\n")
+		lines = spliceLines(lines, line+1, line+1, insert)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
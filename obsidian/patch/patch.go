@@ -0,0 +1,350 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mcp-obsidian/obsidian/frontmatter"
+	"mcp-obsidian/obsidian/markdown"
+	"mcp-obsidian/obsidian/types"
+)
+
+// ErrTargetNotFound is returned when the patch target does not exist in the note
+var ErrTargetNotFound = errors.New("patch target not found")
+
+// HeadingDelimiter separates nested heading names in a heading target
+const HeadingDelimiter = "::"
+
+// blockMarkerPattern matches a trailing ^blockid marker
+var blockMarkerPattern = regexp.MustCompile(`(?:^|\s)\^[A-Za-z0-9-]+\s*$`)
+
+// Apply applies a Local REST API style PATCH operation to note content and
+// returns the updated note. Operation is one of append, prepend or replace and
+// targetType is one of heading, block or frontmatter. The lines and regex
+// targets also accept delete; regex targets the first match, see ApplyRegex
+// for other occurrences.
+func Apply(content, operation, targetType, target, body string) (string, error) {
+	switch operation {
+	case "append", "prepend", "replace":
+	case "delete":
+		if targetType != "lines" && targetType != "regex" {
+			return "", fmt.Errorf("operation delete requires a lines or regex target")
+		}
+	default:
+		return "", fmt.Errorf("invalid operation: %s", operation)
+	}
+
+	switch targetType {
+	case "heading":
+		return applyHeading(content, operation, target, body)
+	case "block":
+		return applyBlock(content, operation, target, body)
+	case "frontmatter":
+		return applyFrontmatter(content, operation, target, body)
+	case "lines":
+		return ApplyLines(content, operation, target, "", body)
+	case "regex":
+		return ApplyRegex(content, operation, target, 1, body)
+	default:
+		return "", fmt.Errorf("invalid target type: %s", targetType)
+	}
+}
+
+// Heading describes a heading found in a note
+type Heading struct {
+	Level   int
+	Title   string
+	Line    int // zero-based line index
+	EndLine int // zero-based index of the last line; the underline of a setext heading
+	Path    []string
+}
+
+// Headings returns every top-level ATX and setext heading as the markdown
+// parser sees them, with its ancestor path
+func Headings(lines []string) []Heading {
+	var headings []Heading
+	var stack []Heading
+
+	for _, element := range markdown.Parse(strings.Join(lines, "\n")) {
+		if element.Type != markdown.TypeHeading {
+			continue
+		}
+
+		heading := Heading{
+			Level:   element.Level,
+			Title:   element.Title,
+			Line:    element.Line - 1,
+			EndLine: element.EndLine - 1,
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		for _, parent := range stack {
+			heading.Path = append(heading.Path, parent.Title)
+		}
+		heading.Path = append(heading.Path, heading.Title)
+
+		stack = append(stack, heading)
+		headings = append(headings, heading)
+	}
+
+	return headings
+}
+
+// applyHeading patches the section that belongs to a (possibly nested) heading
+func applyHeading(content, operation, target, body string) (string, error) {
+	lines := strings.Split(content, "\n")
+	start, end, err := Section(lines, target)
+	if err != nil {
+		return "", err
+	}
+
+	insert := splitBody(body)
+
+	switch operation {
+	case "prepend":
+		lines = spliceLines(lines, start, start, insert)
+	case "append":
+		last := end
+		for last > start && strings.TrimSpace(lines[last-1]) == "" {
+			last--
+		}
+		lines = spliceLines(lines, last, last, insert)
+	case "replace":
+		trailing := []string{}
+		if end < len(lines) {
+			trailing = append(trailing, "")
+		}
+		lines = spliceLines(lines, start, end, append(insert, trailing...))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// Section locates the section of a (possibly nested) heading target. It
+// returns the zero-based line after the heading and the line where the
+// section ends: the next heading of the same or a higher level, or len(lines).
+func Section(lines []string, target string) (int, int, error) {
+	headings := Headings(lines)
+
+	parts := strings.Split(target, HeadingDelimiter)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	index := -1
+	for i, heading := range headings {
+		if headingPathEquals(heading.Path, parts) {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		// Allow a bare heading name when it is unique in the note
+		for i, heading := range headings {
+			if len(parts) == 1 && heading.Title == parts[0] {
+				if index != -1 {
+					return 0, 0, fmt.Errorf("heading %q is ambiguous, use a nested target", target)
+				}
+				index = i
+			}
+		}
+	}
+	if index == -1 {
+		return 0, 0, fmt.Errorf("%w: heading %q", ErrTargetNotFound, target)
+	}
+
+	heading := headings[index]
+	start := heading.EndLine + 1
+	end := len(lines)
+	for _, next := range headings[index+1:] {
+		if next.Level <= heading.Level {
+			end = next.Line
+			break
+		}
+	}
+
+	return start, end, nil
+}
+
+// applyBlock patches the block that carries a ^blockid marker
+func applyBlock(content, operation, target, body string) (string, error) {
+	lines := strings.Split(content, "\n")
+	id := strings.TrimPrefix(strings.TrimSpace(target), "^")
+
+	block, ok := FindBlock(lines, id)
+	if !ok {
+		return "", fmt.Errorf("%w: block %q", ErrTargetNotFound, target)
+	}
+
+	insert := splitBody(body)
+	marker := "^" + id
+
+	switch operation {
+	case "prepend":
+		lines = spliceLines(lines, block.Start, block.Start, insert)
+	case "append":
+		lines = spliceLines(lines, block.End+1, block.End+1, insert)
+	case "replace":
+		replacement := append([]string{}, insert...)
+		if block.Inline {
+			if len(replacement) == 0 {
+				replacement = []string{marker}
+			} else {
+				replacement[len(replacement)-1] = strings.TrimRight(replacement[len(replacement)-1], " \t") + " " + marker
+			}
+		}
+		lines = spliceLines(lines, block.Start, block.ContentEnd+1, replacement)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// Block is a block carrying a ^blockid marker. Line indexes are zero-based.
+type Block struct {
+	Type       string // Element type, e.g. "paragraph", "list_item" or "table"
+	Start      int    // First line of the block
+	End        int    // Last line of the block, including a standalone marker line
+	ContentEnd int    // Last line of the block content, before a standalone marker line
+	MarkerLine int    // Line holding the marker
+	Inline     bool   // Whether the marker follows text on its line
+}
+
+// FindBlock locates the block identified by id through the block IDs the
+// markdown parser records, so markers in code are ignored. A list item
+// includes its nested items; a marker on a line of its own after a blank line
+// belongs to the block before it.
+func FindBlock(lines []string, id string) (Block, bool) {
+	return findBlock(lines, markdown.Parse(strings.Join(lines, "\n")), nil, id)
+}
+
+// findBlock searches elements depth first, preferring the innermost element
+// carrying the ID; parent is the element containing elements
+func findBlock(lines []string, elements []types.MarkdownElement, parent *types.MarkdownElement, id string) (Block, bool) {
+	for i := range elements {
+		element := &elements[i]
+		if block, ok := findBlock(lines, element.Children, element, id); ok {
+			// A marker ending the last paragraph of a quote or callout belongs to it
+			quote := element.Type == markdown.TypeBlockquote || element.Type == markdown.TypeCallout
+			inner := block.Type == markdown.TypeParagraph || block.Type == markdown.TypeBlockquote || block.Type == markdown.TypeCallout
+			if quote && inner && block.End == element.EndLine-1 {
+				block.Type, block.Start = element.Type, element.Line-1
+			}
+			return block, true
+		}
+		if element.Attributes["block_id"] != id {
+			continue
+		}
+
+		block := Block{
+			Type:       element.Type,
+			Start:      element.Line - 1,
+			End:        element.EndLine - 1,
+			ContentEnd: element.EndLine - 1,
+			MarkerLine: element.EndLine - 1,
+		}
+		rest := blockMarkerPattern.ReplaceAllString(lines[block.MarkerLine], "")
+		block.Inline = strings.Trim(rest, " \t>") != ""
+
+		switch {
+		case element.Type == markdown.TypeParagraph && parent != nil && parent.Type == markdown.TypeListItem && i == 0:
+			// The marker ends the text of a list item, which owns its nested items
+			block.Type = parent.Type
+			block.End, block.ContentEnd = parent.EndLine-1, parent.EndLine-1
+			if !block.Inline && block.MarkerLine == block.End {
+				block.ContentEnd--
+			}
+		case block.Inline:
+		case element.Line < element.EndLine:
+			// A marker line continuing a paragraph
+			block.ContentEnd = block.MarkerLine - 1
+		case i > 0:
+			previous := elements[i-1]
+			block.Type = previous.Type
+			block.Start, block.ContentEnd = previous.Line-1, previous.EndLine-1
+		default:
+			block.ContentEnd = block.Start - 1
+		}
+		return block, true
+	}
+	return Block{}, false
+}
+
+// applyFrontmatter patches a single frontmatter field
+func applyFrontmatter(content, operation, target, body string) (string, error) {
+	field := strings.TrimSpace(target)
+	value := frontmatter.DecodeValue(body)
+
+	if operation == "replace" {
+		return frontmatter.SetField(content, field, value)
+	}
+
+	data, err := frontmatter.Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	existing, exists := data[field]
+	if !exists || existing == nil {
+		return frontmatter.SetField(content, field, value)
+	}
+
+	switch current := existing.(type) {
+	case []interface{}:
+		additions, ok := value.([]interface{})
+		if !ok {
+			additions = []interface{}{value}
+		}
+		if operation == "append" {
+			value = append(append([]interface{}{}, current...), additions...)
+		} else {
+			value = append(append([]interface{}{}, additions...), current...)
+		}
+	default:
+		// Join the field as written, so dates and numbers such as 1.10 keep their form
+		text, ok, err := frontmatter.Scalar(content, field)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			text = fmt.Sprint(current)
+		}
+		if operation == "append" {
+			value = text + fmt.Sprint(value)
+		} else {
+			value = fmt.Sprint(value) + text
+		}
+	}
+
+	return frontmatter.SetField(content, field, value)
+}
+
+// headingPathEquals compares a heading path against target parts
+func headingPathEquals(path, parts []string) bool {
+	if len(path) != len(parts) {
+		return false
+	}
+	for i := range path {
+		if path[i] != parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitBody splits patch content into lines, dropping a single trailing newline
+func splitBody(body string) []string {
+	return strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+}
+
+// spliceLines replaces lines[start:end] with insert
+func spliceLines(lines []string, start, end int, insert []string) []string {
+	result := make([]string, 0, len(lines)-(end-start)+len(insert))
+	result = append(result, lines[:start]...)
+	result = append(result, insert...)
+	result = append(result, lines[end:]...)
+	return result
+}
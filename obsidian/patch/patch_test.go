@@ -0,0 +1,132 @@
+package patch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyHeading(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		target  string
+		want    string
+	}{
+		{
+			name:    "setext heading",
+			content: "Title\n=====\n\nbody\n",
+			target:  "Title",
+			want:    "Title\n=====\n\nbody\nNEW\n",
+		},
+		{
+			name:    "nested setext headings",
+			content: "Top\n===\nSub\n---\ntext\n# Next\n",
+			target:  "Top::Sub",
+			want:    "Top\n===\nSub\n---\ntext\nNEW\n# Next\n",
+		},
+		{
+			name:    "heading in fenced code is ignored",
+			content: "# A\nx\n```\n# B\n```\n## B\ny\n",
+			target:  "A::B",
+			want:    "# A\nx\n```\n# B\n```\n## B\ny\nNEW\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(tt.content, "append", "heading", tt.target, "NEW")
+			if err != nil {
+				t.Fatalf("Apply returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Block
+	}{
+		{
+			name:    "marker in code is ignored",
+			content: "```\ntext ^a\n```\n\npara ^a\n",
+			want:    Block{Type: "paragraph", Start: 4, End: 4, ContentEnd: 4, MarkerLine: 4, Inline: true},
+		},
+		{
+			name:    "list item with nested items",
+			content: "- parent ^a\n  - child\n  - child2\n- other\n",
+			want:    Block{Type: "list_item", Start: 0, End: 2, ContentEnd: 2, MarkerLine: 0, Inline: true},
+		},
+		{
+			name:    "marker line after a table",
+			content: "| a | b |\n| - | - |\n| 1 | 2 |\n\n^a\n\nafter\n",
+			want:    Block{Type: "table", Start: 0, End: 4, ContentEnd: 2, MarkerLine: 4},
+		},
+		{
+			name:    "marker line continuing a paragraph",
+			content: "line one\nline two\n^a\n",
+			want:    Block{Type: "paragraph", Start: 0, End: 2, ContentEnd: 1, MarkerLine: 2},
+		},
+		{
+			name:    "blockquote",
+			content: "> quote\n> more ^a\n",
+			want:    Block{Type: "blockquote", Start: 0, End: 1, ContentEnd: 1, MarkerLine: 1, Inline: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FindBlock(strings.Split(tt.content, "\n"), "a")
+			if !ok {
+				t.Fatalf("FindBlock found no block")
+			}
+			if got != tt.want {
+				t.Errorf("FindBlock = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyFrontmatterScalar(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		operation string
+		target    string
+		body      string
+		want      string
+	}{
+		{
+			name:      "append to a date",
+			content:   "---\ndue: 2026-03-01\n---\nbody\n",
+			operation: "append",
+			target:    "due",
+			body:      "x",
+			want:      "---\ndue: 2026-03-01x\n---\nbody\n",
+		},
+		{
+			name:      "prepend to a number as written",
+			content:   "---\nversion: 1.10\n---\n",
+			operation: "prepend",
+			target:    "version",
+			body:      "v",
+			want:      "---\nversion: v1.10\n---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(tt.content, tt.operation, "frontmatter", tt.target, tt.body)
+			if err != nil {
+				t.Fatalf("Apply returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
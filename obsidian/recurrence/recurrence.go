@@ -0,0 +1,364 @@
+// Package recurrence parses the recurrence rules of the Obsidian Tasks plugin,
+// such as "every week on Monday" or "every month on the last Friday when done",
+// and computes the date of the next occurrence.
+package recurrence
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unit is the calendar unit a rule repeats in
+type Unit string
+
+// Supported units
+const (
+	Day   Unit = "day"
+	Week  Unit = "week"
+	Month Unit = "month"
+	Year  Unit = "year"
+)
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Interval int            // Repeat every Interval units, at least 1
+	Unit     Unit           // Calendar unit
+	Weekdays []time.Weekday // Week rules: days of the week, Monday first; empty keeps the weekday of the reference date
+	MonthDay int            // Month rules: day of the month, -1 for the last day; 0 keeps the day of the reference date
+	Nth      int            // Month rules: 1-5 for the nth Weekday of the month, -1 for the last one
+	Weekday  time.Weekday   // Month rules: weekday used with Nth
+	WhenDone bool           // Next occurrence counts from the completion date instead of the reference date
+}
+
+var (
+	rulePattern     = regexp.MustCompile(`^(?:(\d+|other) )?([a-z]+?)s?(?: on (?:the )?(.+))?$`)
+	monthDayPattern = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+	nthPattern      = regexp.MustCompile(`^(\d)(?:st|nd|rd|th) ([a-z]+)$`)
+)
+
+// weekdays maps weekday names and abbreviations to weekdays
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// Parse parses a rule as written after 🔁. Supported forms:
+//
+//	every day, every 3 days
+//	every weekday
+//	every week, every other week, every 2 weeks on Monday, Friday
+//	every Monday, every Tuesday and Thursday
+//	every month, every month on the 15th, every month on the last day,
+//	every month on the 2nd Tuesday, every month on the last Friday
+//	every year, every 2 years
+//
+// Any rule may end in "when done".
+func Parse(text string) (Rule, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+
+	rule := Rule{Interval: 1}
+	if strings.HasSuffix(normalized, " when done") {
+		rule.WhenDone = true
+		normalized = strings.TrimSuffix(normalized, " when done")
+	}
+
+	rest := strings.TrimPrefix(normalized, "every ")
+	if rest == normalized {
+		return Rule{}, fmt.Errorf("unsupported recurrence rule %q", text)
+	}
+
+	// "every monday", "every tuesday and thursday"
+	if days, err := parseWeekdays(rest); err == nil {
+		rule.Unit = Week
+		rule.Weekdays = days
+		return rule, nil
+	}
+
+	match := rulePattern.FindStringSubmatch(rest)
+	if match == nil {
+		return Rule{}, fmt.Errorf("unsupported recurrence rule %q", text)
+	}
+
+	switch match[1] {
+	case "":
+	case "other":
+		rule.Interval = 2
+	default:
+		interval, err := strconv.Atoi(match[1])
+		if err != nil || interval < 1 {
+			return Rule{}, fmt.Errorf("invalid interval in recurrence rule %q", text)
+		}
+		rule.Interval = interval
+	}
+
+	unit, on := match[2], match[3]
+	switch {
+	case unit == "day" && on == "":
+		rule.Unit = Day
+	case unit == "weekday" && on == "" && match[1] == "":
+		rule.Unit = Week
+		rule.Weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	case unit == "week":
+		rule.Unit = Week
+		if on != "" {
+			days, err := parseWeekdays(on)
+			if err != nil {
+				return Rule{}, fmt.Errorf("invalid recurrence rule %q: %w", text, err)
+			}
+			rule.Weekdays = days
+		}
+	case unit == "month":
+		rule.Unit = Month
+		if on != "" {
+			if err := parseMonthDay(&rule, on); err != nil {
+				return Rule{}, fmt.Errorf("invalid recurrence rule %q: %w", text, err)
+			}
+		}
+	case unit == "year" && on == "":
+		rule.Unit = Year
+	default:
+		return Rule{}, fmt.Errorf("unsupported recurrence rule %q", text)
+	}
+
+	return rule, nil
+}
+
+// parseWeekdays parses a list such as "monday, wednesday and friday"
+func parseWeekdays(text string) ([]time.Weekday, error) {
+	seen := make(map[time.Weekday]bool)
+	var days []time.Weekday
+	for _, name := range strings.FieldsFunc(strings.ReplaceAll(text, " and ", ","), func(r rune) bool { return r == ',' || r == ' ' }) {
+		day, ok := weekdays[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", name)
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no weekdays given")
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return mondayIndex(days[i]) < mondayIndex(days[j])
+	})
+	return days, nil
+}
+
+// parseMonthDay parses the "on the ..." part of a month rule
+func parseMonthDay(rule *Rule, text string) error {
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "last" || text == "last day":
+		rule.MonthDay = -1
+		return nil
+	case strings.HasPrefix(text, "last "):
+		day, ok := weekdays[strings.TrimPrefix(text, "last ")]
+		if !ok {
+			return fmt.Errorf("unknown weekday in %q", text)
+		}
+		rule.Nth, rule.Weekday = -1, day
+		return nil
+	}
+
+	if match := monthDayPattern.FindStringSubmatch(text); match != nil {
+		day, _ := strconv.Atoi(match[1])
+		if day < 1 || day > 31 {
+			return fmt.Errorf("invalid day of the month %q", text)
+		}
+		rule.MonthDay = day
+		return nil
+	}
+
+	if match := nthPattern.FindStringSubmatch(text); match != nil {
+		nth, _ := strconv.Atoi(match[1])
+		day, ok := weekdays[match[2]]
+		if !ok || nth < 1 || nth > 5 {
+			return fmt.Errorf("invalid weekday of the month %q", text)
+		}
+		rule.Nth, rule.Weekday = nth, day
+		return nil
+	}
+
+	return fmt.Errorf("unsupported day of the month %q", text)
+}
+
+// Next returns the first occurrence after from. Times are truncated to the day.
+//
+// Month and year steps keep the day of the month where possible and clamp to
+// the last day of shorter months, so "every month" from 31 January falls on
+// the last day of February rather than skipping it.
+func (r Rule) Next(from time.Time) time.Time {
+	from = truncateDay(from)
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Unit {
+	case Day:
+		return from.AddDate(0, 0, interval)
+	case Week:
+		if len(r.Weekdays) == 0 {
+			return from.AddDate(0, 0, 7*interval)
+		}
+		return r.nextWeekday(from, interval)
+	case Month:
+		return r.nextInMonth(from, interval)
+	case Year:
+		return addMonths(from, 12*interval, from.Day())
+	}
+	return from
+}
+
+// Occurrence holds the dates of one occurrence of a recurring task; unset
+// dates are zero
+type Occurrence struct {
+	Start     time.Time
+	Scheduled time.Time
+	Due       time.Time
+}
+
+// NextOccurrence returns the dates of the occurrence following o, the way the
+// Tasks plugin computes them. The reference date is the due date, else the
+// scheduled date, else the start date; it moves to the next date of the rule,
+// counted from done for "when done" rules, and the other dates move by the
+// same number of days. An occurrence without dates stays without dates.
+func (r Rule) NextOccurrence(o Occurrence, done time.Time) Occurrence {
+	reference := o.Due
+	if reference.IsZero() {
+		reference = o.Scheduled
+	}
+	if reference.IsZero() {
+		reference = o.Start
+	}
+	if reference.IsZero() {
+		return Occurrence{}
+	}
+
+	reference = truncateDay(reference)
+	base := reference
+	if r.WhenDone {
+		base = truncateDay(done.In(reference.Location()))
+	}
+	next := r.Next(base)
+	days := daysBetween(reference, next)
+
+	shift := func(date time.Time) time.Time {
+		if date.IsZero() {
+			return date
+		}
+		return truncateDay(date).AddDate(0, 0, days)
+	}
+	return Occurrence{
+		Start:     shift(o.Start),
+		Scheduled: shift(o.Scheduled),
+		Due:       shift(o.Due),
+	}
+}
+
+// nextWeekday returns the next listed weekday, moving on by interval weeks
+// (weeks start on Monday) once the current week has none left
+func (r Rule) nextWeekday(from time.Time, interval int) time.Time {
+	current := mondayIndex(from.Weekday())
+	for _, day := range r.Weekdays {
+		if index := mondayIndex(day); index > current {
+			return from.AddDate(0, 0, index-current)
+		}
+	}
+
+	weekStart := from.AddDate(0, 0, -current+7*interval)
+	return weekStart.AddDate(0, 0, mondayIndex(r.Weekdays[0]))
+}
+
+// nextInMonth returns the next occurrence of a month rule
+func (r Rule) nextInMonth(from time.Time, interval int) time.Time {
+	if r.MonthDay == 0 && r.Nth == 0 {
+		return addMonths(from, interval, from.Day())
+	}
+
+	// The rule's day in the current month counts if it is still ahead
+	first := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	if day, ok := r.dayInMonth(first); ok && day.After(from) {
+		return day
+	}
+
+	// Months without the day (a 5th Friday) are skipped
+	for step := interval; step <= 12*5*interval; step += interval {
+		if day, ok := r.dayInMonth(first.AddDate(0, step, 0)); ok {
+			return day
+		}
+	}
+	return addMonths(from, interval, from.Day())
+}
+
+// dayInMonth returns the rule's day in the month starting at first
+func (r Rule) dayInMonth(first time.Time) (time.Time, bool) {
+	last := daysIn(first)
+	switch {
+	case r.MonthDay == -1:
+		return first.AddDate(0, 0, last-1), true
+	case r.MonthDay > 0:
+		day := r.MonthDay
+		if day > last {
+			day = last
+		}
+		return first.AddDate(0, 0, day-1), true
+	case r.Nth == -1:
+		day := first.AddDate(0, 0, last-1)
+		for day.Weekday() != r.Weekday {
+			day = day.AddDate(0, 0, -1)
+		}
+		return day, true
+	default:
+		offset := (int(r.Weekday) - int(first.Weekday()) + 7) % 7
+		day := 1 + offset + 7*(r.Nth-1)
+		if day > last {
+			return time.Time{}, false
+		}
+		return first.AddDate(0, 0, day-1), true
+	}
+}
+
+// addMonths adds months to a date, using day as the day of the month and
+// clamping it to the length of the target month
+func addMonths(from time.Time, months, day int) time.Time {
+	first := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()).AddDate(0, months, 0)
+	if last := daysIn(first); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// daysIn returns the number of days in the month starting at first
+func daysIn(first time.Time) int {
+	return first.AddDate(0, 1, -1).Day()
+}
+
+// mondayIndex numbers weekdays from Monday (0) to Sunday (6)
+func mondayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// truncateDay returns midnight of the day of t
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween returns the number of calendar days from one midnight to another
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
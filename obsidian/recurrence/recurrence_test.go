@@ -0,0 +1,157 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+const layout = "2006-01-02"
+
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+	if value == "" {
+		return time.Time{}
+	}
+	parsed, err := time.Parse(layout, value)
+	if err != nil {
+		t.Fatalf("invalid test date %q: %v", value, err)
+	}
+	return parsed
+}
+
+func format(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.Format(layout)
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		from string
+		want string
+	}{
+		{"every day", "every day", "2026-10-17", "2026-10-18"},
+		{"every 3 days", "every 3 days", "2026-10-17", "2026-10-20"},
+		{"every other day", "every other day", "2026-10-17", "2026-10-19"},
+		{"every week", "every week", "2026-10-17", "2026-10-24"},
+		{"every 2 weeks", "every 2 weeks", "2026-10-17", "2026-10-31"},
+		{"weekday from friday", "every weekday", "2026-10-16", "2026-10-19"},
+		{"weekday from saturday", "every weekday", "2026-10-17", "2026-10-19"},
+		{"weekday from monday", "every weekday", "2026-10-19", "2026-10-20"},
+		{"listed weekday later this week", "every week on Monday, Friday", "2026-10-14", "2026-10-16"},
+		{"listed weekday next week", "every week on Monday, Friday", "2026-10-16", "2026-10-19"},
+		{"weekday every 2 weeks", "every 2 weeks on Monday", "2026-10-12", "2026-10-26"},
+		{"bare weekdays", "every tuesday and thursday", "2026-10-17", "2026-10-20"},
+		{"bare sunday ends the week", "every Sunday", "2026-10-17", "2026-10-18"},
+		{"every month", "every month", "2026-10-17", "2026-11-17"},
+		{"every month clamps to february", "every month", "2026-01-31", "2026-02-28"},
+		{"every 3 months", "every 3 months", "2026-11-30", "2027-02-28"},
+		{"day of month later this month", "every month on the 15th", "2026-10-10", "2026-10-15"},
+		{"day of month next month", "every month on the 15th", "2026-10-17", "2026-11-15"},
+		{"31st in february", "every month on the 31st", "2026-01-31", "2026-02-28"},
+		{"31st after february", "every month on the 31st", "2026-02-28", "2026-03-31"},
+		{"last day", "every month on the last day", "2026-02-10", "2026-02-28"},
+		{"last day next month", "every month on the last", "2026-02-28", "2026-03-31"},
+		{"2nd tuesday", "every month on the 2nd Tuesday", "2026-10-17", "2026-11-10"},
+		{"last friday", "every month on the last Friday", "2026-10-17", "2026-10-30"},
+		{"5th friday this month", "every month on the 5th Friday", "2026-10-17", "2026-10-30"},
+		{"5th friday skips short months", "every month on the 5th Friday", "2026-10-30", "2027-01-29"},
+		{"every year", "every year", "2026-10-17", "2027-10-17"},
+		{"every year from leap day", "every year", "2028-02-29", "2029-02-28"},
+		{"every 2 years", "every 2 years", "2026-10-17", "2028-10-17"},
+		{"when done is ignored by Next", "Every Week When Done", "2026-10-17", "2026-10-24"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.rule, err)
+			}
+
+			if got := format(rule.Next(date(t, tt.from))); got != tt.want {
+				t.Errorf("Parse(%q).Next(%s) = %s, want %s", tt.rule, tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	rule, err := Parse("every 2 weeks on friday, Mon when done")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if rule.Interval != 2 || rule.Unit != Week || !rule.WhenDone {
+		t.Errorf("Parse = %+v, want interval 2, unit week, when done", rule)
+	}
+	if len(rule.Weekdays) != 2 || rule.Weekdays[0] != time.Monday || rule.Weekdays[1] != time.Friday {
+		t.Errorf("Parse weekdays = %v, want [Monday Friday]", rule.Weekdays)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"daily",
+		"every",
+		"every fortnight",
+		"every 0 days",
+		"every 2 weekdays",
+		"every day on monday",
+		"every week on funday",
+		"every month on the 32nd",
+		"every month on the 6th friday",
+		"every year on march 3",
+	}
+
+	for _, text := range tests {
+		t.Run(text, func(t *testing.T) {
+			if rule, err := Parse(text); err == nil {
+				t.Errorf("Parse(%q) = %+v, want error", text, rule)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      string
+		start     string
+		scheduled string
+		due       string
+		done      string
+		want      [3]string // start, scheduled, due
+	}{
+		{"due and scheduled move together", "every week", "", "2026-10-18", "2026-10-20", "2026-10-17", [3]string{"", "2026-10-25", "2026-10-27"}},
+		{"when done counts from completion", "every week when done", "", "", "2026-10-01", "2026-10-17", [3]string{"", "", "2026-10-24"}},
+		{"other dates keep their offset", "every month", "2026-01-25", "", "2026-01-31", "2026-01-31", [3]string{"2026-02-22", "", "2026-02-28"}},
+		{"scheduled is the reference without due", "every day", "", "2026-10-17", "", "2026-10-20", [3]string{"", "2026-10-18", ""}},
+		{"start is the last resort", "every 2 days", "2026-10-17", "", "", "2026-10-17", [3]string{"2026-10-19", "", ""}},
+		{"no dates stay without dates", "every day", "", "", "", "2026-10-17", [3]string{"", "", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.rule, err)
+			}
+
+			next := rule.NextOccurrence(Occurrence{
+				Start:     date(t, tt.start),
+				Scheduled: date(t, tt.scheduled),
+				Due:       date(t, tt.due),
+			}, date(t, tt.done))
+
+			got := [3]string{format(next.Start), format(next.Scheduled), format(next.Due)}
+			if got != tt.want {
+				t.Errorf("NextOccurrence = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
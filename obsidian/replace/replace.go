@@ -0,0 +1,249 @@
+// Package replace finds and replaces text in notes, optionally limited to the
+// frontmatter or the body and skipping code, and renders the changes as
+// unified diffs.
+package replace
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"mcp-obsidian/obsidian/markdown"
+	"mcp-obsidian/obsidian/types"
+)
+
+// Scopes limit the part of a note that is searched
+const (
+	ScopeAll         = "all"
+	ScopeBody        = "body"
+	ScopeFrontmatter = "frontmatter"
+)
+
+// Options describes a find and replace
+type Options struct {
+	Pattern     *regexp.Regexp // Compiled search, see Compile
+	Replacement string         // Replacement text; $1 and ${name} expand for regex searches
+	Regex       bool           // Whether Pattern came from a regular expression
+	Scope       string         // ScopeAll, ScopeBody or ScopeFrontmatter
+	SkipCode    bool           // Leave code blocks and inline code untouched
+}
+
+// Compile builds the search pattern for a literal string or a regular
+// expression. ^ and $ match at line boundaries.
+func Compile(find string, regex, caseSensitive, wholeWord bool) (*regexp.Regexp, error) {
+	if find == "" {
+		return nil, fmt.Errorf("search text must not be empty")
+	}
+
+	expr := find
+	if !regex {
+		expr = regexp.QuoteMeta(find)
+	}
+	if wholeWord {
+		expr = `\b(?:` + expr + `)\b`
+	}
+	flags := "(?m)"
+	if !caseSensitive {
+		flags = "(?mi)"
+	}
+
+	pattern, err := regexp.Compile(flags + expr)
+	if err != nil {
+		// Report the problem without the flags added here
+		var syntaxErr *syntax.Error
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("invalid regex %q: %s", find, syntaxErr.Code)
+		}
+		return nil, fmt.Errorf("invalid regex %q: %v", find, err)
+	}
+	return pattern, nil
+}
+
+// ValidateScope checks a scope name, treating "" as ScopeAll
+func ValidateScope(scope string) (string, error) {
+	switch scope {
+	case "", ScopeAll:
+		return ScopeAll, nil
+	case ScopeBody, ScopeFrontmatter:
+		return scope, nil
+	}
+	return "", fmt.Errorf("invalid scope: %s. Must be one of: all, body, frontmatter", scope)
+}
+
+// Apply replaces every match inside the searched regions of content and
+// returns the new content with the number of replacements. Matches that
+// reach outside a searched region are left alone.
+func Apply(content string, opts Options) (string, int) {
+	regions := searchRegions(content, opts.Scope, opts.SkipCode)
+
+	var buf strings.Builder
+	last, count := 0, 0
+	for _, match := range opts.Pattern.FindAllStringSubmatchIndex(content, -1) {
+		if match[1] == match[0] || !insideRegion(regions, match[0], match[1]) {
+			continue
+		}
+
+		buf.WriteString(content[last:match[0]])
+		if opts.Regex {
+			buf.Write(opts.Pattern.ExpandString(nil, opts.Replacement, content, match))
+		} else {
+			buf.WriteString(opts.Replacement)
+		}
+		last = match[1]
+		count++
+	}
+	if count == 0 {
+		return content, 0
+	}
+
+	buf.WriteString(content[last:])
+	return buf.String(), count
+}
+
+// span is a half-open byte range of a note
+type span struct {
+	start, end int
+}
+
+// insideRegion reports whether [start, end) lies within one of the regions
+func insideRegion(regions []span, start, end int) bool {
+	for _, region := range regions {
+		if start >= region.start && end <= region.end {
+			return true
+		}
+	}
+	return false
+}
+
+// searchRegions returns the byte ranges of content that are searched
+func searchRegions(content, scope string, skipCode bool) []span {
+	// offsets[i] is the byte offset of 1-based line i+1; the extra entry marks the end
+	offsets := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	offsets = append(offsets, len(content))
+	lineSpan := func(first, last int) span {
+		return span{offsets[first-1], offsets[min(last, len(offsets)-1)]}
+	}
+
+	// Lines of the frontmatter delimiters, 0 without frontmatter
+	elements := markdown.Parse(content)
+	yamlOpen, yamlClose := 0, 0
+	if len(elements) > 0 && elements[0].Type == markdown.TypeFrontmatter {
+		yamlOpen, yamlClose = elements[0].Line, elements[0].EndLine
+	}
+
+	var regions []span
+	switch scope {
+	case ScopeFrontmatter:
+		// Only the YAML between the delimiter lines
+		if yamlClose-yamlOpen < 2 {
+			return nil
+		}
+		return []span{lineSpan(yamlOpen+1, yamlClose-1)}
+	case ScopeBody:
+		if yamlClose+1 > len(offsets)-1 {
+			return nil
+		}
+		regions = []span{lineSpan(yamlClose+1, len(offsets)-1)}
+	default:
+		regions = []span{{0, len(content)}}
+	}
+
+	if !skipCode {
+		return regions
+	}
+
+	var code []span
+	var collect func(elements []types.MarkdownElement)
+	collect = func(elements []types.MarkdownElement) {
+		for _, element := range elements {
+			if element.Type == markdown.TypeCodeBlock {
+				code = append(code, lineSpan(element.Line, element.EndLine))
+				continue
+			}
+			collect(element.Children)
+		}
+	}
+	collect(elements)
+
+	inBlock := func(offset int) bool {
+		for _, block := range code {
+			if offset >= block.start && offset < block.end {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i+1 < len(offsets); i++ {
+		if !inBlock(offsets[i]) {
+			code = append(code, inlineCode(content[offsets[i]:offsets[i+1]], offsets[i])...)
+		}
+	}
+
+	return subtract(regions, code)
+}
+
+// inlineCode returns the code spans of a line, as backtick runs closed by a
+// run of the same length; base is the offset of the line
+func inlineCode(line string, base int) []span {
+	var spans []span
+	for i := 0; i < len(line); {
+		if line[i] != '`' {
+			i++
+			continue
+		}
+		n := 0
+		for i+n < len(line) && line[i+n] == '`' {
+			n++
+		}
+
+		closed := false
+		for j := i + n; j < len(line); {
+			if line[j] != '`' {
+				j++
+				continue
+			}
+			m := 0
+			for j+m < len(line) && line[j+m] == '`' {
+				m++
+			}
+			if m == n {
+				spans = append(spans, span{base + i, base + j + m})
+				i, closed = j+m, true
+				break
+			}
+			j += m
+		}
+		if !closed {
+			i += n
+		}
+	}
+	return spans
+}
+
+// subtract removes the excluded ranges from regions
+func subtract(regions, excluded []span) []span {
+	for _, cut := range excluded {
+		var kept []span
+		for _, region := range regions {
+			if cut.end <= region.start || cut.start >= region.end {
+				kept = append(kept, region)
+				continue
+			}
+			if cut.start > region.start {
+				kept = append(kept, span{region.start, cut.start})
+			}
+			if cut.end < region.end {
+				kept = append(kept, span{cut.end, region.end})
+			}
+		}
+		regions = kept
+	}
+	return regions
+}
@@ -0,0 +1,119 @@
+package replace
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	note := "---\nowner: Alice\n---\n# Alice's project\n\nAlice wrote `Alice.run()` here.\n\n```\nAlice\n```\nMalice stays.\n"
+
+	tests := []struct {
+		name      string
+		find      string
+		replace   string
+		regex     bool
+		ignore    bool
+		wholeWord bool
+		scope     string
+		skipCode  bool
+		want      string
+		count     int
+	}{
+		{
+			name: "literal everywhere", find: "Alice", replace: "Bob", scope: ScopeAll,
+			want:  "---\nowner: Bob\n---\n# Bob's project\n\nBob wrote `Bob.run()` here.\n\n```\nBob\n```\nMalice stays.\n",
+			count: 5,
+		},
+		{
+			name: "whole word skips code", find: "Alice", replace: "Bob", wholeWord: true, scope: ScopeAll, skipCode: true,
+			want:  "---\nowner: Bob\n---\n# Bob's project\n\nBob wrote `Alice.run()` here.\n\n```\nAlice\n```\nMalice stays.\n",
+			count: 3,
+		},
+		{
+			name: "body only", find: "alice", replace: "Bob", ignore: true, wholeWord: true, scope: ScopeBody, skipCode: true,
+			want:  "---\nowner: Alice\n---\n# Bob's project\n\nBob wrote `Alice.run()` here.\n\n```\nAlice\n```\nMalice stays.\n",
+			count: 2,
+		},
+		{
+			name: "frontmatter only", find: "Alice", replace: "Bob", scope: ScopeFrontmatter,
+			want:  "---\nowner: Bob\n---\n# Alice's project\n\nAlice wrote `Alice.run()` here.\n\n```\nAlice\n```\nMalice stays.\n",
+			count: 1,
+		},
+		{
+			name: "regex with groups", find: `^# (\w+)'s (\w+)$`, replace: "# The $2 of $1", regex: true, scope: ScopeAll,
+			want:  "---\nowner: Alice\n---\n# The project of Alice\n\nAlice wrote `Alice.run()` here.\n\n```\nAlice\n```\nMalice stays.\n",
+			count: 1,
+		},
+		{
+			name: "literal keeps dollar signs", find: "Malice", replace: "$1 Malice", scope: ScopeAll,
+			want:  "---\nowner: Alice\n---\n# Alice's project\n\nAlice wrote `Alice.run()` here.\n\n```\nAlice\n```\n$1 Malice stays.\n",
+			count: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := Compile(tt.find, tt.regex, !tt.ignore, tt.wholeWord)
+			if err != nil {
+				t.Fatalf("Compile(%q) returned error: %v", tt.find, err)
+			}
+
+			got, count := Apply(note, Options{
+				Pattern:     pattern,
+				Replacement: tt.replace,
+				Regex:       tt.regex,
+				Scope:       tt.scope,
+				SkipCode:    tt.skipCode,
+			})
+			if got != tt.want || count != tt.count {
+				t.Errorf("Apply = %q (%d replacements), want %q (%d)", got, count, tt.want, tt.count)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	before := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	after := strings.Replace(strings.Replace(before, "two", "TWO", 1), "nine", "NINE", 1)
+
+	want := `--- a/n.md
++++ b/n.md
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+@@ -8,3 +8,3 @@
+ eight
+-nine
++NINE
+ ten
+`
+	if got := Unified("n.md", before, after, 1); got != want {
+		t.Errorf("Unified with 1 context line =\n%s\nwant\n%s", got, want)
+	}
+
+	// Changes closer than twice the context share a hunk
+	if got := Unified("n.md", before, after, 3); strings.Count(got, "@@ -") != 1 || !strings.Contains(got, "@@ -1,10 +1,10 @@") {
+		t.Errorf("Unified with 3 context lines =\n%s\nwant one hunk over all lines", got)
+	}
+
+	if got := Unified("n.md", before, before, 3); got != "" {
+		t.Errorf("Unified of equal content = %q, want empty", got)
+	}
+}
+
+func TestUnifiedInsertAndNewline(t *testing.T) {
+	want := `--- a/n.md
++++ b/n.md
+@@ -2,1 +2,2 @@
+-b
+\ No newline at end of file
++inserted
++b
+`
+	if got := Unified("n.md", "a\nb", "a\ninserted\nb\n", 0); got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,358 @@
+// Package tables reads GFM tables from a note as rows of cells, edits them
+// and renders them back with aligned pipes.
+package tables
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mcp-obsidian/obsidian/markdown"
+	"mcp-obsidian/obsidian/types"
+)
+
+// Table is a GFM table found in a note. Cells are kept as written, with
+// escaped pipes and inline Markdown, so untouched cells render unchanged.
+type Table struct {
+	Columns    []string   // Header cells
+	Alignments []string   // left, right, center or none for each column
+	Rows       [][]string // Body rows, each with one cell per column
+	Line       int        // 1-based line of the header row
+	EndLine    int        // 1-based last line of the table, including a block ID line
+	BlockID    string     // Block ID written on its own line after the last row
+}
+
+// blockIDLine matches a line that holds only a ^block-id
+var blockIDLine = regexp.MustCompile(`^\s*\^([A-Za-z0-9-]+)\s*$`)
+
+// Find returns the top-level tables of a note in document order. Tables in
+// blockquotes, callouts and lists are not included.
+func Find(content string) []Table {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	var found []Table
+	for _, element := range markdown.Parse(content) {
+		if element.Type != markdown.TypeTable {
+			continue
+		}
+		found = append(found, fromElement(element, lines))
+	}
+	return found
+}
+
+// fromElement splits the lines of a table element into cells
+func fromElement(element types.MarkdownElement, lines []string) Table {
+	table := Table{
+		Columns:    markdown.SplitTableRow(lines[element.Line-1]),
+		Alignments: strings.Split(element.Attributes["alignments"], ","),
+		Line:       element.Line,
+		EndLine:    element.EndLine,
+	}
+
+	for i := element.Line + 1; i < element.EndLine; i++ {
+		text := lines[i]
+		if i == element.EndLine-1 {
+			if match := blockIDLine.FindStringSubmatch(text); match != nil {
+				table.BlockID = match[1]
+				break
+			}
+		}
+		table.Rows = append(table.Rows, fitRow(markdown.SplitTableRow(text), len(table.Columns)))
+	}
+	return table
+}
+
+// fitRow pads or truncates a row to n cells, as GFM renders it
+func fitRow(cells []string, n int) []string {
+	row := make([]string, n)
+	copy(row, cells)
+	return row
+}
+
+// Keys returns the JSON keys of the columns: the header text, made unique by
+// numbering repeated headers and naming empty ones after their position
+func (t Table) Keys() []string {
+	keys := make([]string, len(t.Columns))
+	seen := make(map[string]int)
+	for i, column := range t.Columns {
+		key := Value(column)
+		if key == "" {
+			key = fmt.Sprintf("column_%d", i+1)
+		}
+		seen[key]++
+		if seen[key] > 1 {
+			key = fmt.Sprintf("%s_%d", key, seen[key])
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// Records returns the rows as maps from column key to cell value
+func (t Table) Records() []map[string]string {
+	keys := t.Keys()
+	records := make([]map[string]string, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		record := make(map[string]string, len(keys))
+		for i, key := range keys {
+			record[key] = Value(row[i])
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Value returns the text of a cell with escaped pipes unescaped
+func Value(cell string) string {
+	return strings.ReplaceAll(cell, `\|`, "|")
+}
+
+// Cell returns a value written as a table cell: pipes are escaped and line
+// breaks become <br>, which Obsidian renders inside tables
+func Cell(value string) string {
+	value = strings.ReplaceAll(value, `\|`, "|")
+	value = strings.ReplaceAll(value, "|", `\|`)
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.TrimSpace(strings.ReplaceAll(value, "\n", "<br>"))
+}
+
+// Column returns the index of a column by key or header text, preferring an
+// exact match over a case-insensitive one
+func (t Table) Column(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	keys := t.Keys()
+	for i, key := range keys {
+		if key == name {
+			return i, nil
+		}
+	}
+	for i, key := range keys {
+		if strings.EqualFold(key, name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown column %q, columns are: %s", name, strings.Join(keys, ", "))
+}
+
+// Match returns the indexes of the rows whose cells equal every value in
+// where. Values are compared after trimming, ignoring case.
+func (t Table) Match(where map[string]string) ([]int, error) {
+	columns := make(map[int]string, len(where))
+	for name, value := range where {
+		i, err := t.Column(name)
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = strings.TrimSpace(value)
+	}
+
+	var matched []int
+	for r, row := range t.Rows {
+		ok := true
+		for i, value := range columns {
+			if !strings.EqualFold(Value(row[i]), value) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, r)
+		}
+	}
+	return matched, nil
+}
+
+// AddRow appends a row; columns missing from values are left empty
+func (t *Table) AddRow(values map[string]string) error {
+	row := make([]string, len(t.Columns))
+	if err := t.setCells(row, values); err != nil {
+		return err
+	}
+	t.Rows = append(t.Rows, row)
+	return nil
+}
+
+// UpdateRows sets the given cells of the rows at indexes
+func (t *Table) UpdateRows(indexes []int, values map[string]string) error {
+	for _, r := range indexes {
+		if err := t.setCells(t.Rows[r], values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setCells writes values into row by column name
+func (t *Table) setCells(row []string, values map[string]string) error {
+	for name, value := range values {
+		i, err := t.Column(name)
+		if err != nil {
+			return err
+		}
+		row[i] = Cell(value)
+	}
+	return nil
+}
+
+// DeleteRows removes the rows at indexes
+func (t *Table) DeleteRows(indexes []int) {
+	remove := make(map[int]bool, len(indexes))
+	for _, r := range indexes {
+		remove[r] = true
+	}
+
+	rows := t.Rows[:0]
+	for r, row := range t.Rows {
+		if !remove[r] {
+			rows = append(rows, row)
+		}
+	}
+	t.Rows = rows
+}
+
+// AddColumn appends a column, filling existing rows with value
+func (t *Table) AddColumn(name, value string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("column name must not be empty")
+	}
+	if _, err := t.Column(name); err == nil {
+		return fmt.Errorf("column %q already exists", name)
+	}
+
+	t.Columns = append(t.Columns, Cell(name))
+	t.Alignments = append(t.Alignments, "none")
+	for r := range t.Rows {
+		t.Rows[r] = append(t.Rows[r], Cell(value))
+	}
+	return nil
+}
+
+// DeleteColumn removes a column and its cells
+func (t *Table) DeleteColumn(name string) error {
+	i, err := t.Column(name)
+	if err != nil {
+		return err
+	}
+	if len(t.Columns) == 1 {
+		return fmt.Errorf("cannot delete the only column of a table")
+	}
+
+	t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+	t.Alignments = append(t.Alignments[:i], t.Alignments[i+1:]...)
+	for r, row := range t.Rows {
+		t.Rows[r] = append(row[:i], row[i+1:]...)
+	}
+	return nil
+}
+
+// RenameColumn changes the header of a column
+func (t *Table) RenameColumn(name, newName string) error {
+	i, err := t.Column(name)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(newName) == "" {
+		return fmt.Errorf("column name must not be empty")
+	}
+	if j, err := t.Column(newName); err == nil && j != i {
+		return fmt.Errorf("column %q already exists", newName)
+	}
+
+	t.Columns[i] = Cell(newName)
+	return nil
+}
+
+// Sort orders the rows by a column. Cells that are both numbers compare
+// numerically, others case-insensitively; empty cells sort last. The sort
+// is stable, so sorting by several columns in turn orders by all of them.
+func (t *Table) Sort(name string, descending bool) error {
+	i, err := t.Column(name)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(t.Rows, func(a, b int) bool {
+		x, y := strings.TrimSpace(Value(t.Rows[a][i])), strings.TrimSpace(Value(t.Rows[b][i]))
+		if x == "" || y == "" {
+			return x != "" && y == ""
+		}
+		if descending {
+			x, y = y, x
+		}
+		return lessCell(x, y)
+	})
+	return nil
+}
+
+// lessCell compares two non-empty cells
+func lessCell(x, y string) bool {
+	a, errA := strconv.ParseFloat(strings.ReplaceAll(x, ",", ""), 64)
+	b, errB := strconv.ParseFloat(strings.ReplaceAll(y, ",", ""), 64)
+	if errA == nil && errB == nil {
+		return a < b
+	}
+	return strings.ToLower(x) < strings.ToLower(y)
+}
+
+// Render returns the lines of the table with every column padded to the
+// width of its widest cell, so the pipes line up in source mode
+func (t Table) Render() []string {
+	widths := make([]int, len(t.Columns))
+	for i, column := range t.Columns {
+		widths[i] = max(3, displayWidth(column))
+		for _, row := range t.Rows {
+			widths[i] = max(widths[i], displayWidth(row[i]))
+		}
+	}
+
+	delimiter := make([]string, len(t.Columns))
+	for i := range t.Columns {
+		dashes := widths[i]
+		switch t.alignment(i) {
+		case "left":
+			delimiter[i] = ":" + strings.Repeat("-", dashes-1)
+		case "right":
+			delimiter[i] = strings.Repeat("-", dashes-1) + ":"
+		case "center":
+			delimiter[i] = ":" + strings.Repeat("-", dashes-2) + ":"
+		default:
+			delimiter[i] = strings.Repeat("-", dashes)
+		}
+	}
+
+	lines := []string{t.renderRow(t.Columns, widths), "| " + strings.Join(delimiter, " | ") + " |"}
+	for _, row := range t.Rows {
+		lines = append(lines, t.renderRow(row, widths))
+	}
+	if t.BlockID != "" {
+		lines = append(lines, "^"+t.BlockID)
+	}
+	return lines
+}
+
+// renderRow pads the cells of a row according to the column alignments
+func (t Table) renderRow(cells []string, widths []int) string {
+	padded := make([]string, len(cells))
+	for i, cell := range cells {
+		gap := widths[i] - displayWidth(cell)
+		switch t.alignment(i) {
+		case "right":
+			padded[i] = strings.Repeat(" ", gap) + cell
+		case "center":
+			padded[i] = strings.Repeat(" ", gap/2) + cell + strings.Repeat(" ", gap-gap/2)
+		default:
+			padded[i] = cell + strings.Repeat(" ", gap)
+		}
+	}
+	return "| " + strings.Join(padded, " | ") + " |"
+}
+
+// alignment returns the alignment of column i
+func (t Table) alignment(i int) string {
+	if i < len(t.Alignments) {
+		return t.Alignments[i]
+	}
+	return "none"
+}
@@ -0,0 +1,29 @@
+package tables
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderWideCharacters(t *testing.T) {
+	table := Table{
+		Columns:    []string{"Name", "Note"},
+		Alignments: []string{"left", "right"},
+		Rows: [][]string{
+			{"日本語", "x"},
+			{"abc", "🎉"},
+			{"e\u0301", "café"}, // e with a combining acute accent
+		},
+	}
+
+	want := []string{
+		"| Name   | Note |",
+		"| :----- | ---: |",
+		"| 日本語 |    x |",
+		"| abc    |   🎉 |",
+		"| e\u0301      | café |",
+	}
+	if got := table.Render(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Render =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,131 @@
+package tags
+
+import (
+	"regexp"
+	"strings"
+
+	"mcp-obsidian/obsidian/frontmatter"
+	"mcp-obsidian/obsidian/markdown"
+	"mcp-obsidian/obsidian/types"
+)
+
+// inlineTagPattern matches Obsidian inline tags such as #tag or #project/alpha.
+// A tag must follow the start of a line or whitespace.
+var inlineTagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_\-/]+)`)
+
+// numericPattern matches tags made only of digits, which Obsidian does not treat as tags
+var numericPattern = regexp.MustCompile(`^[\d/]+$`)
+
+// Normalize strips the leading # and surrounding whitespace from a tag
+func Normalize(tag string) string {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimPrefix(tag, "#")
+	return strings.Trim(tag, "/")
+}
+
+// Parents returns the ancestors of a nested tag, e.g. "a/b/c" yields ["a", "a/b"]
+func Parents(tag string) []string {
+	var parents []string
+	parts := strings.Split(tag, "/")
+	for i := 1; i < len(parts); i++ {
+		parents = append(parents, strings.Join(parts[:i], "/"))
+	}
+	return parents
+}
+
+// Extract returns the unique tags of a note, from both the frontmatter
+// tags/tag fields and inline #tags outside code. Tags are returned without
+// the leading # in order of first appearance.
+func Extract(content string) []string {
+	var found []string
+	seen := make(map[string]bool)
+
+	add := func(tag string) {
+		tag = Normalize(tag)
+		if tag == "" || numericPattern.MatchString(tag) {
+			return
+		}
+		key := strings.ToLower(tag)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		found = append(found, tag)
+	}
+
+	if data, err := frontmatter.Parse(content); err == nil {
+		for _, field := range []string{"tags", "tag"} {
+			for _, tag := range frontmatterTags(data[field]) {
+				add(tag)
+			}
+		}
+	}
+
+	for _, line := range codeFreeLines(content) {
+		for _, match := range inlineTagPattern.FindAllStringSubmatch(line, -1) {
+			add(match[1])
+		}
+	}
+
+	return found
+}
+
+// frontmatterTags converts a frontmatter tags value (list or delimited string) into tags
+func frontmatterTags(value interface{}) []string {
+	var result []string
+	switch v := value.(type) {
+	case string:
+		for _, tag := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' }) {
+			result = append(result, tag)
+		}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// codeFreeLines returns the lines of a note outside its frontmatter and code
+// blocks, as found by the markdown parser, with inline code spans blanked out
+func codeFreeLines(content string) []string {
+	skip := make(map[int]bool)
+	markdown.Walk(markdown.Parse(content), func(element types.MarkdownElement) {
+		switch element.Type {
+		case markdown.TypeFrontmatter, markdown.TypeCodeBlock, markdown.TypeMermaid:
+			for line := element.Line; line <= element.EndLine; line++ {
+				skip[line] = true
+			}
+		}
+	})
+
+	var lines []string
+	for i, line := range strings.Split(content, "\n") {
+		if !skip[i+1] {
+			lines = append(lines, stripInlineCode(line))
+		}
+	}
+	return lines
+}
+
+// stripInlineCode removes `code spans` from a line
+func stripInlineCode(line string) string {
+	if !strings.Contains(line, "`") {
+		return line
+	}
+	var buf strings.Builder
+	inCode := false
+	for _, r := range line {
+		if r == '`' {
+			inCode = !inCode
+			buf.WriteRune(' ')
+			continue
+		}
+		if !inCode {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
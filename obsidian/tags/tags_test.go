@@ -0,0 +1,53 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "frontmatter and inline tags",
+			content: "---\ntags: [alpha, \"#beta\"]\ntag: gamma\n---\ntext #delta and #alpha/child\n",
+			want:    []string{"alpha", "beta", "gamma", "delta", "alpha/child"},
+		},
+		{
+			name:    "case-insensitive duplicates and numbers",
+			content: "#Tag #tag #123 #2024/01 #y2024\n",
+			want:    []string{"Tag", "y2024"},
+		},
+		{
+			name:    "fenced and inline code",
+			content: "```\n#fenced\n```\n~~~~\n```\n#still-fenced\n~~~~\n`#span` #real\n",
+			want:    []string{"real"},
+		},
+		{
+			name:    "indented code and fences in lists",
+			content: "para\n\n    #indented\n\n- item\n  ```\n  #listed\n  ```\n- #item\n",
+			want:    []string{"item"},
+		},
+		{
+			name:    "unclosed fence in a blockquote ends with the quote",
+			content: "> ```\n> #quoted\n\n#after\n",
+			want:    []string{"after"},
+		},
+		{
+			name:    "mermaid",
+			content: "```mermaid\ngraph TD\nA-->B #note\n```\n",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extract(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
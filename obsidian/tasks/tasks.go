@@ -0,0 +1,321 @@
+// Package tasks parses Markdown checkbox tasks together with the emoji
+// metadata of the Obsidian Tasks plugin (📅 due, ⏳ scheduled, 🔁 recurrence,
+// ⏫ priority, ...) and edits task lines in place.
+package tasks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"mcp-obsidian/obsidian/markdown"
+	"mcp-obsidian/obsidian/recurrence"
+	"mcp-obsidian/obsidian/tags"
+	"mcp-obsidian/obsidian/types"
+)
+
+// Status names
+const (
+	StatusTodo       = "todo"
+	StatusDone       = "done"
+	StatusInProgress = "in_progress"
+	StatusCancelled  = "cancelled"
+	StatusOther      = "other"
+)
+
+// Date fields
+const (
+	FieldDue       = "due"
+	FieldScheduled = "scheduled"
+	FieldStart     = "start"
+	FieldCreated   = "created"
+	FieldDone      = "done"
+	FieldCancelled = "cancelled"
+)
+
+// dateLayout is the YYYY-MM-DD layout of Tasks plugin dates
+const dateLayout = "2006-01-02"
+
+// dateField describes how a Tasks plugin date is written
+type dateField struct {
+	emoji   string         // Emoji written for new values
+	pattern *regexp.Regexp // Matches the emoji (any accepted variant) and its date
+}
+
+// dateFields lists the date fields in the order the Tasks plugin writes them
+var dateFields = []struct {
+	name string
+	dateField
+}{
+	{FieldCreated, newDateField("➕", "➕")},
+	{FieldStart, newDateField("🛫", "🛫")},
+	{FieldScheduled, newDateField("⏳", "⏳⌛")},
+	{FieldDue, newDateField("📅", "📅📆🗓")},
+	{FieldDone, newDateField("✅", "✅")},
+	{FieldCancelled, newDateField("❌", "❌")},
+}
+
+// newDateField builds the pattern for a date written after one of the emojis
+func newDateField(emoji, variants string) dateField {
+	return dateField{
+		emoji:   emoji,
+		pattern: regexp.MustCompile(`[ \t]*[` + variants + `]\x{FE0F}?[ \t]*(\d{4}-\d{2}-\d{2})`),
+	}
+}
+
+// priorities maps priority emojis to priority names
+var priorities = map[string]string{
+	"🔺": "highest",
+	"⏫": "high",
+	"🔼": "medium",
+	"🔽": "low",
+	"⏬": "lowest",
+}
+
+var (
+	priorityPattern   = regexp.MustCompile(`[ \t]*(🔺|⏫|🔼|🔽|⏬)\x{FE0F}?`)
+	recurrencePattern = regexp.MustCompile(`[ \t]*🔁\x{FE0F}?[ \t]*([A-Za-z0-9, !]*[A-Za-z0-9!])`)
+	blockIDSuffix     = regexp.MustCompile(`[ \t]+\^[A-Za-z0-9-]+[ \t]*$`)
+
+	// taskLinePattern matches the checkbox of a task line, also inside blockquotes and callouts
+	taskLinePattern = regexp.MustCompile(`^([ \t]*(?:>[ \t]?)*[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]+\[)(.)(\])`)
+)
+
+// StatusName returns the name of a status character
+func StatusName(status string) string {
+	switch status {
+	case " ":
+		return StatusTodo
+	case "x", "X":
+		return StatusDone
+	case "/":
+		return StatusInProgress
+	case "-":
+		return StatusCancelled
+	}
+	return StatusOther
+}
+
+// StatusChar returns the status character for a status name, or the
+// argument itself when it is already a single status character
+func StatusChar(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case StatusTodo:
+		return " ", nil
+	case StatusDone:
+		return "x", nil
+	case StatusInProgress:
+		return "/", nil
+	case StatusCancelled:
+		return "-", nil
+	}
+	if len([]rune(name)) == 1 {
+		return name, nil
+	}
+	return "", fmt.Errorf("unknown task status %q", name)
+}
+
+// Parse returns the tasks of a note in document order. Tasks in code blocks
+// are ignored; tasks in blockquotes and callouts are included.
+func Parse(path, content string) []types.Task {
+	var found []types.Task
+	collect(markdown.Parse(content), path, 0, 0, &found)
+	return found
+}
+
+// collect walks elements, tracking the enclosing task for nesting
+func collect(elements []types.MarkdownElement, path string, parent, level int, found *[]types.Task) {
+	for _, element := range elements {
+		status, ok := element.Attributes["task"]
+		if element.Type != markdown.TypeListItem || !ok {
+			collect(element.Children, path, parent, level, found)
+			continue
+		}
+
+		task := parseTask(element.Title, status)
+		task.Path = path
+		task.Line = element.Line
+		task.Level = level + 1
+		task.Parent = parent
+		if task.BlockID == "" && element.EndLine == element.Line {
+			task.BlockID = element.Attributes["block_id"]
+		}
+		*found = append(*found, task)
+
+		collect(element.Children, path, element.Line, level+1, found)
+	}
+}
+
+// parseTask reads the metadata of a task description
+func parseTask(title, status string) types.Task {
+	task := types.Task{
+		Status:     status,
+		StatusName: StatusName(status),
+	}
+
+	text := title
+	// Items with subtasks keep the ^id of their own line in the title
+	if loc := blockIDSuffix.FindStringIndex(text); loc != nil {
+		task.BlockID = strings.TrimPrefix(strings.TrimSpace(text[loc[0]:]), "^")
+		text = text[:loc[0]]
+	}
+	for _, field := range dateFields {
+		if match := field.pattern.FindStringSubmatch(text); match != nil {
+			setDate(&task, field.name, match[1])
+			text = field.pattern.ReplaceAllString(text, "")
+		}
+	}
+	if match := priorityPattern.FindStringSubmatch(text); match != nil {
+		task.Priority = priorities[match[1]]
+		text = priorityPattern.ReplaceAllString(text, "")
+	}
+	if match := recurrencePattern.FindStringSubmatch(text); match != nil {
+		task.Recurrence = strings.TrimSpace(match[1])
+		text = recurrencePattern.ReplaceAllString(text, "")
+	}
+
+	task.Text = strings.Join(strings.Fields(text), " ")
+	task.Tags = tags.Extract(task.Text)
+	return task
+}
+
+// setDate stores a date field on a task
+func setDate(task *types.Task, field, value string) {
+	switch field {
+	case FieldDue:
+		task.Due = value
+	case FieldScheduled:
+		task.Scheduled = value
+	case FieldStart:
+		task.Start = value
+	case FieldCreated:
+		task.Created = value
+	case FieldDone:
+		task.Done = value
+	case FieldCancelled:
+		task.Cancelled = value
+	}
+}
+
+// Find returns the task at a line or, when blockID is set, the task carrying that block ID
+func Find(found []types.Task, line int, blockID string) (types.Task, bool) {
+	blockID = strings.TrimPrefix(strings.TrimSpace(blockID), "^")
+	for _, task := range found {
+		if blockID != "" {
+			if strings.EqualFold(task.BlockID, blockID) {
+				return task, true
+			}
+			continue
+		}
+		if task.Line == line {
+			return task, true
+		}
+	}
+	return types.Task{}, false
+}
+
+// SetStatus replaces the status character of a task line
+func SetStatus(line, status string) (string, error) {
+	match := taskLinePattern.FindStringSubmatchIndex(line)
+	if match == nil {
+		return "", fmt.Errorf("not a task line: %s", strings.TrimSpace(line))
+	}
+	return line[:match[4]] + status + line[match[5]:], nil
+}
+
+// SetDate sets, replaces or (with an empty value) removes a date field of a
+// task line. New fields are appended before a trailing block ID.
+func SetDate(line, field, value string) (string, error) {
+	for _, candidate := range dateFields {
+		if candidate.name != field {
+			continue
+		}
+
+		written := " " + candidate.emoji + " " + value
+		if value == "" {
+			written = ""
+		}
+		if loc := candidate.pattern.FindStringIndex(line); loc != nil {
+			return line[:loc[0]] + written + line[loc[1]:], nil
+		}
+		if value == "" {
+			return line, nil
+		}
+
+		suffix := ""
+		if loc := blockIDSuffix.FindStringIndex(line); loc != nil {
+			line, suffix = line[:loc[0]], line[loc[0]:]
+		}
+		return strings.TrimRight(line, " \t") + written + suffix, nil
+	}
+	return "", fmt.Errorf("unknown task date field %q", field)
+}
+
+// NextOccurrence returns the line of the next occurrence of a recurring task:
+// the task unchecked, without its done, cancelled and block ID markers, and
+// with its start, scheduled and due dates moved forward by its 🔁 rule. A
+// created date becomes the completion date. line is the task line as written
+// and done the completion date in YYYY-MM-DD format.
+func NextOccurrence(line string, task types.Task, done string) (string, error) {
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return "", err
+	}
+
+	doneDate, err := time.Parse(dateLayout, done)
+	if err != nil {
+		return "", fmt.Errorf("invalid done date %q", done)
+	}
+
+	var current recurrence.Occurrence
+	for _, date := range []struct {
+		value  string
+		target *time.Time
+	}{
+		{task.Start, &current.Start},
+		{task.Scheduled, &current.Scheduled},
+		{task.Due, &current.Due},
+	} {
+		if date.value == "" {
+			continue
+		}
+		if *date.target, err = time.Parse(dateLayout, date.value); err != nil {
+			return "", fmt.Errorf("invalid task date %q", date.value)
+		}
+	}
+	next := rule.NextOccurrence(current, doneDate)
+
+	if line, err = SetStatus(line, " "); err != nil {
+		return "", err
+	}
+	if loc := blockIDSuffix.FindStringIndex(line); loc != nil {
+		line = line[:loc[0]]
+	}
+
+	changes := [][2]string{
+		{FieldDone, ""},
+		{FieldCancelled, ""},
+		{FieldStart, formatDate(next.Start)},
+		{FieldScheduled, formatDate(next.Scheduled)},
+		{FieldDue, formatDate(next.Due)},
+	}
+	if task.Created != "" {
+		changes = append(changes, [2]string{FieldCreated, done})
+	}
+	for _, change := range changes {
+		if line, err = SetDate(line, change[0], change[1]); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimRight(line, " \t"), nil
+}
+
+// formatDate formats a date, or returns "" for the zero time
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(dateLayout)
+}
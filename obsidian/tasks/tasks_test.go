@@ -0,0 +1,159 @@
+package tasks
+
+import (
+	"reflect"
+	"testing"
+
+	"mcp-obsidian/obsidian/types"
+)
+
+func TestParse(t *testing.T) {
+	content := "- [ ] plain\n" +
+		"- [x] finished ✅ 2026-01-02\n" +
+		"- [/] started\n" +
+		"- [-] dropped ❌ 2026-01-03\n" +
+		"- [?] question\n" +
+		"- [ ] report ⏫ 🔁 every week 📅 2026-01-05 ⏳ 2026-01-04 🛫 2026-01-01 ➕ 2025-12-30 #work ^rep\n" +
+		"- [ ] variant 📆 2026-02-01 ⌛️2026-01-31 🔽\n" +
+		"- [ ] parent\n" +
+		"    - [ ] child\n" +
+		"        - plain item\n" +
+		"            - [x] grandchild\n" +
+		"```\n" +
+		"- [ ] in code\n" +
+		"```\n" +
+		"> [!todo] Callout\n" +
+		"> - [ ] quoted\n" +
+		"- not a task\n"
+
+	want := []types.Task{
+		{Line: 1, Status: " ", StatusName: StatusTodo, Text: "plain", Level: 1},
+		{Line: 2, Status: "x", StatusName: StatusDone, Text: "finished", Level: 1, Done: "2026-01-02"},
+		{Line: 3, Status: "/", StatusName: StatusInProgress, Text: "started", Level: 1},
+		{Line: 4, Status: "-", StatusName: StatusCancelled, Text: "dropped", Level: 1, Cancelled: "2026-01-03"},
+		{Line: 5, Status: "?", StatusName: StatusOther, Text: "question", Level: 1},
+		{
+			Line: 6, Status: " ", StatusName: StatusTodo, Text: "report #work", Level: 1,
+			Due: "2026-01-05", Scheduled: "2026-01-04", Start: "2026-01-01", Created: "2025-12-30",
+			Recurrence: "every week", Priority: "high", Tags: []string{"work"}, BlockID: "rep",
+		},
+		{Line: 7, Status: " ", StatusName: StatusTodo, Text: "variant", Level: 1, Due: "2026-02-01", Scheduled: "2026-01-31", Priority: "low"},
+		{Line: 8, Status: " ", StatusName: StatusTodo, Text: "parent", Level: 1},
+		{Line: 9, Status: " ", StatusName: StatusTodo, Text: "child", Level: 2, Parent: 8},
+		{Line: 11, Status: "x", StatusName: StatusDone, Text: "grandchild", Level: 3, Parent: 9},
+		{Line: 16, Status: " ", StatusName: StatusTodo, Text: "quoted", Level: 1},
+	}
+	for i := range want {
+		want[i].Path = "note.md"
+	}
+
+	got := Parse("note.md", content)
+	if len(got) != len(want) {
+		t.Fatalf("Parse found %d tasks, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("task %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestStatusChar(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"todo", " ", false},
+		{"Done", "x", false},
+		{"in_progress", "/", false},
+		{"cancelled", "-", false},
+		{">", ">", false},
+		{"later", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := StatusChar(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("StatusChar(%q) = %q, %v, want %q (error %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	found := Parse("note.md", "- [ ] one\n- [ ] two ^Two\n")
+
+	if task, ok := Find(found, 1, ""); !ok || task.Text != "one" {
+		t.Errorf("Find(line 1) = %+v, %v, want the task on line 1", task, ok)
+	}
+	if task, ok := Find(found, 0, "^two"); !ok || task.Line != 2 {
+		t.Errorf("Find(^two) = %+v, %v, want the task on line 2", task, ok)
+	}
+	if _, ok := Find(found, 3, ""); ok {
+		t.Errorf("Find(line 3) found a task on a line without one")
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"- [ ] task", "- [x] task"},
+		{"  * [/] nested", "  * [x] nested"},
+		{"1. [ ] numbered", "1. [x] numbered"},
+		{"> - [ ] quoted", "> - [x] quoted"},
+	}
+
+	for _, tt := range tests {
+		got, err := SetStatus(tt.line, "x")
+		if err != nil || got != tt.want {
+			t.Errorf("SetStatus(%q) = %q, %v, want %q", tt.line, got, err, tt.want)
+		}
+	}
+
+	if _, err := SetStatus("- plain item", "x"); err == nil {
+		t.Errorf("SetStatus accepted a line without a checkbox")
+	}
+}
+
+func TestSetDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		field string
+		value string
+		want  string
+	}{
+		{"add", "- [ ] task", FieldDue, "2026-01-01", "- [ ] task 📅 2026-01-01"},
+		{"add before block ID", "- [ ] task ^id", FieldDone, "2026-01-01", "- [ ] task ✅ 2026-01-01 ^id"},
+		{"replace a variant", "- [ ] task 📆 2025-12-01 #tag", FieldDue, "2026-01-01", "- [ ] task 📅 2026-01-01 #tag"},
+		{"remove", "- [ ] task ⏳ 2026-01-01 ^id", FieldScheduled, "", "- [ ] task ^id"},
+		{"remove missing", "- [ ] task", FieldStart, "", "- [ ] task"},
+	}
+
+	for _, tt := range tests {
+		got, err := SetDate(tt.line, tt.field, tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: SetDate(%q) = %q, %v, want %q", tt.name, tt.line, got, err, tt.want)
+		}
+	}
+
+	if _, err := SetDate("- [ ] task", "deadline", "2026-01-01"); err == nil {
+		t.Errorf("SetDate accepted an unknown field")
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	line := "- [x] water plants 🔁 every week ➕ 2025-12-01 ⏳ 2026-01-03 📅 2026-01-05 ✅ 2026-01-06 ^plants"
+	task := Parse("note.md", line+"\n")[0]
+
+	got, err := NextOccurrence(line, task, "2026-01-06")
+	if err != nil {
+		t.Fatalf("NextOccurrence returned error: %v", err)
+	}
+	want := "- [ ] water plants 🔁 every week ➕ 2026-01-06 ⏳ 2026-01-10 📅 2026-01-12"
+	if got != want {
+		t.Errorf("NextOccurrence = %q, want %q", got, want)
+	}
+}
@@ -18,6 +18,33 @@ type FileInfo struct {
 	CreatedTime  time.Time `json:"createdTime,omitempty"`
 }
 
+// FileTreeNode represents a file or folder in a recursive directory listing
+type FileTreeNode struct {
+	Name      string          `json:"name"`
+	Path      string          `json:"path"` // Vault-relative path
+	Type      string          `json:"type"` // "file" or "directory"
+	Size      int64           `json:"size,omitempty"`
+	Modified  string          `json:"modified,omitempty"`
+	Children  []*FileTreeNode `json:"children,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"` // Folder below max_depth whose contents were not listed
+}
+
+// NoteJSON represents a note in the application/vnd.olrapi.note+json format
+type NoteJSON struct {
+	Path        string                 `json:"path"`
+	Content     string                 `json:"content"`
+	Frontmatter map[string]interface{} `json:"frontmatter"`
+	Tags        []string               `json:"tags"`
+	Stat        NoteStat               `json:"stat"`
+}
+
+// NoteStat holds file statistics of a note; times are Unix milliseconds
+type NoteStat struct {
+	Ctime int64 `json:"ctime"` // 0 when the filesystem does not record when the file was created
+	Mtime int64 `json:"mtime"`
+	Size  int64 `json:"size"`
+}
+
 // DirectoryInfo represents a directory in the Obsidian vault
 type DirectoryInfo struct {
 	Path         string    `json:"path"`
@@ -32,6 +59,14 @@ type SearchResult struct {
 	Filename string        `json:"filename"`
 	Score    float64       `json:"score"`
 	Matches  []SearchMatch `json:"matches"`
+	Result   interface{}   `json:"result,omitempty"` // Value returned by JsonLogic queries
+}
+
+// DQLRow represents one row of a Dataview TABLE query result
+type DQLRow struct {
+	Filename string                 `json:"filename"`
+	Columns  []string               `json:"-"`      // Column headers in query order
+	Values   map[string]interface{} `json:"values"` // Column values keyed by header
 }
 
 // SearchMatch represents a match within a search result
@@ -48,11 +83,12 @@ type MatchPos struct {
 
 // MarkdownElement represents any element in a markdown file
 type MarkdownElement struct {
-	Type       string            `json:"type"`       // "heading", "paragraph", "list", "code_block", "table", "blockquote", "link", "image"
+	Type       string            `json:"type"`       // "heading", "paragraph", "list", "list_item", "code_block", "table", "blockquote", "link", "image", ...
 	Level      int               `json:"level"`      // For headings (1-6), list items (1+)
 	Content    string            `json:"content"`    // The actual content
 	Title      string            `json:"title"`      // For headings, links, images
 	Line       int               `json:"line"`       // Line number where element starts
+	EndLine    int               `json:"endLine"`    // Line number where element ends
 	Attributes map[string]string `json:"attributes"` // Additional attributes (e.g., language for code blocks)
 	Children   []MarkdownElement `json:"children"`   // For nested elements
 }
@@ -153,6 +189,66 @@ type TagInfo struct {
 	Files []string `json:"files"`
 }
 
+// NoteLink represents a link from a note to another note, a heading, a block
+// or an attachment
+type NoteLink struct {
+	Source   string `json:"source"`             // Vault path of the linking note
+	Line     int    `json:"line"`               // Line of the link in the source note
+	Raw      string `json:"raw"`                // Link as written
+	Kind     string `json:"kind"`               // "wikilink" or "markdown"
+	Embed    bool   `json:"embed,omitempty"`    // ![[embed]] or ![image](path)
+	Target   string `json:"target"`             // Link target as written, without heading or block
+	Heading  string `json:"heading,omitempty"`  // Heading subpath, nested headings joined with "#"
+	Block    string `json:"block,omitempty"`    // Block ID without the ^ prefix
+	Alias    string `json:"alias,omitempty"`    // Display text
+	Resolved string `json:"resolved,omitempty"` // Vault path the link points to; empty when unresolved
+}
+
+// NoteNeighbor represents a file reachable from a note through links
+type NoteNeighbor struct {
+	Path      string `json:"path"`
+	Distance  int    `json:"distance"`  // Number of links from the starting note
+	Via       string `json:"via"`       // Note it was reached from
+	Direction string `json:"direction"` // "outgoing" or "incoming", relative to Via
+}
+
+// LinkRewrite represents a link whose text changes because a file moved
+type LinkRewrite struct {
+	Line int    `json:"line"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// LinkFinding represents a problem found by the link linter
+type LinkFinding struct {
+	Kind    string `json:"kind"`           // "unresolved_link", "missing_heading", "missing_block", "orphan_note" or "unused_attachment"
+	Path    string `json:"path"`           // File the finding is about
+	Line    int    `json:"line"`           // Line of the link; 1 for file-level findings
+	Link    string `json:"link,omitempty"` // Link as written
+	Message string `json:"message"`
+}
+
+// Task represents a checkbox task in a note, with Tasks plugin metadata
+type Task struct {
+	Path       string   `json:"path"`
+	Line       int      `json:"line"`                 // 1-based line of the checkbox
+	Status     string   `json:"status"`               // Character between the brackets, e.g. " ", "x", "/" or "-"
+	StatusName string   `json:"statusName"`           // "todo", "done", "in_progress", "cancelled" or "other"
+	Text       string   `json:"text"`                 // Description without dates, priority and recurrence
+	Level      int      `json:"level"`                // Nesting depth among tasks; 1 for top-level tasks
+	Parent     int      `json:"parent,omitempty"`     // Line of the enclosing task
+	Due        string   `json:"due,omitempty"`        // YYYY-MM-DD
+	Scheduled  string   `json:"scheduled,omitempty"`  // YYYY-MM-DD
+	Start      string   `json:"start,omitempty"`      // YYYY-MM-DD
+	Created    string   `json:"created,omitempty"`    // YYYY-MM-DD
+	Done       string   `json:"done,omitempty"`       // YYYY-MM-DD
+	Cancelled  string   `json:"cancelled,omitempty"`  // YYYY-MM-DD
+	Recurrence string   `json:"recurrence,omitempty"` // Recurrence rule as written, e.g. "every week"
+	Priority   string   `json:"priority,omitempty"`   // "highest", "high", "medium", "low" or "lowest"
+	Tags       []string `json:"tags,omitempty"`
+	BlockID    string   `json:"blockId,omitempty"`
+}
+
 // FrontmatterRequest represents a request for frontmatter operations
 type FrontmatterRequest struct {
 	Path   string                 `json:"path"`
@@ -174,10 +270,20 @@ type BlockReferenceRequest struct {
 
 // BlockReferenceResponse represents a response for block references
 type BlockReferenceResponse struct {
-	Path    string `json:"path"`
-	ID      string `json:"id"`
-	Content string `json:"content"`
-	Exists  bool   `json:"exists"`
+	Path        string   `json:"path"`
+	ID          string   `json:"id"`
+	Content     string   `json:"content"`
+	Exists      bool     `json:"exists"`
+	BlockType   string   `json:"blockType,omitempty"`   // Element type, e.g. "paragraph", "list_item", "table", "blockquote" or "callout"
+	StartLine   int      `json:"startLine,omitempty"`   // 1-based first line of the block
+	EndLine     int      `json:"endLine,omitempty"`     // 1-based last line of the block, including a standalone ^id line
+	HeadingPath []string `json:"headingPath,omitempty"` // Headings enclosing the block, outermost first
+}
+
+// Command represents an Obsidian command palette entry
+type Command struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 // ObsidianConfig represents the configuration for Obsidian client
@@ -187,9 +293,20 @@ type ObsidianConfig struct {
 	Port      string
 	Protocol  string
 	VaultPath string
+	Backend   string // "rest" (Local REST API plugin) or "filesystem" (direct access to VaultPath)
 	UseHTTPS  bool
 	Timeout   int
 	VerifySSL bool
+
+	// Time zone (IANA name) and first day of the week used to resolve relative
+	// dates such as "today" or "last week"; empty means UTC and Monday
+	Timezone  string
+	WeekStart string
+
+	// Command IDs agents may execute; entries may use glob patterns such as "editor:*".
+	// An empty allowlist allows every command that is not denied.
+	CommandAllowlist []string
+	CommandDenylist  []string
 }
 
 // NewObsidianConfig creates a new ObsidianConfig with defaults
@@ -198,6 +315,7 @@ func NewObsidianConfig() *ObsidianConfig {
 		Host:      "127.0.0.1",
 		Port:      "27124",
 		Protocol:  "https",
+		Backend:   "rest",
 		UseHTTPS:  true,
 		Timeout:   30,
 		VerifySSL: false,